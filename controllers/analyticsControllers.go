@@ -0,0 +1,316 @@
+package controllers
+
+import (
+	"context"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// applicationAnalyticsStages matches the user's applications that were
+// actually sent and annotates each with:
+//   - reached: index in models.FunnelStages of the furthest stage reached
+//   - applied_on: applied_at, or when the application moved to applied
+//   - first_response: the first status change that means the company replied
+//   - responded, interviewed: flags for the group stages to sum
+//
+// The analytics filters are the same as the application list filters.
+func applicationAnalyticsStages(ctx context.Context, c *gin.Context) (mongo.Pipeline, bool) {
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return nil, false
+	}
+	query, ok := listQuery(ctx, c, userId, models.ApplicationTarget)
+	if !ok {
+		return nil, false
+	}
+	filter, err := applicationFilter(query, userId)
+	if err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	funnel := bson.A{}
+	for _, status := range models.FunnelStages {
+		funnel = append(funnel, status)
+	}
+	responses := bson.A{}
+	for _, status := range models.ResponseStatuses {
+		responses = append(responses, status)
+	}
+	interviewing := 0
+	for i, status := range models.FunnelStages {
+		if status == models.StatusInterviewing {
+			interviewing = i
+		}
+	}
+	history := bson.D{{"$ifNull", bson.A{"$status_history", bson.A{}}}}
+
+	matchStage := bson.D{{"$match", filter}}
+	historyStage := bson.D{
+		{"$addFields", bson.D{
+			// Applications created before status history was kept only
+			// have their current status.
+			{"stages", bson.D{{"$concatArrays", bson.A{
+				bson.D{{"$ifNull", bson.A{"$status_history.to", bson.A{}}}},
+				bson.A{"$status"},
+			}}}},
+			{"applied_on", bson.D{{"$ifNull", bson.A{
+				"$applied_at",
+				bson.D{{"$arrayElemAt", bson.A{
+					bson.D{{"$map", bson.D{
+						{"input", bson.D{{"$filter", bson.D{
+							{"input", history},
+							{"as", "h"},
+							{"cond", bson.D{{"$eq", bson.A{"$$h.to", models.StatusApplied}}}},
+						}}}},
+						{"as", "h"},
+						{"in", "$$h.at"},
+					}}},
+					0,
+				}}},
+			}}}},
+			{"first_response", bson.D{{"$arrayElemAt", bson.A{
+				bson.D{{"$filter", bson.D{
+					{"input", history},
+					{"as", "h"},
+					{"cond", bson.D{{"$in", bson.A{"$$h.to", responses}}}},
+				}}},
+				0,
+			}}}},
+		}},
+	}
+	reachedStage := bson.D{
+		{"$addFields", bson.D{
+			{"reached", bson.D{{"$max", bson.D{{"$map", bson.D{
+				{"input", "$stages"},
+				{"as", "s"},
+				{"in", bson.D{{"$indexOfArray", bson.A{funnel, "$$s"}}}},
+			}}}}}},
+			{"responded", bson.D{{"$ne", bson.A{bson.D{{"$type", "$first_response"}}, "missing"}}}},
+		}},
+	}
+	flagStage := bson.D{
+		{"$addFields", bson.D{
+			{"interviewed", bson.D{{"$gte", bson.A{"$reached", interviewing}}}},
+		}},
+	}
+	// Saved or withdrawn-before-applying applications never reached the
+	// funnel and would only dilute the rates.
+	sentStage := bson.D{{"$match", bson.D{{"reached", bson.D{{"$gte", 0}}}}}}
+
+	return mongo.Pipeline{matchStage, historyStage, reachedStage, flagStage, sentStage}, true
+}
+
+// responseRateGroup sums the counters of models.ResponseRate.
+func responseRateGroup(id interface{}) bson.D {
+	return bson.D{
+		{"$group", bson.D{
+			{"_id", id},
+			{"applications", bson.D{{"$sum", 1}}},
+			{"responses", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$responded", 1, 0}}}}}},
+			{"interviews", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$interviewed", 1, 0}}}}}},
+		}},
+	}
+}
+
+// GetApplicationFunnel reports how many applications reached each stage and
+// the conversion rate between consecutive stages.
+func GetApplicationFunnel() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		pipeline, ok := applicationAnalyticsStages(ctx, c)
+		if !ok {
+			return
+		}
+		groupStage := bson.D{{"$group", bson.D{{"_id", "$reached"}, {"count", bson.D{{"$sum", 1}}}}}}
+
+		cursor, err := applicationCollection.Aggregate(ctx, append(pipeline, groupStage))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing funnel")
+			return
+		}
+		var furthest []struct {
+			Reached int `bson:"_id"`
+			Count   int `bson:"count"`
+		}
+		if err := cursor.All(ctx, &furthest); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing funnel")
+			return
+		}
+
+		// An application that got to a stage also passed every stage before it.
+		reached := make([]int, len(models.FunnelStages))
+		for _, group := range furthest {
+			for i := 0; i <= group.Reached && i < len(reached); i++ {
+				reached[i] += group.Count
+			}
+		}
+
+		returnResponse(c, http.StatusOK, utils.BuildFunnel(reached))
+	}
+}
+
+// GetResponseTime reports the median time from applying to the company's
+// first reply.
+func GetResponseTime() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		pipeline, ok := applicationAnalyticsStages(ctx, c)
+		if !ok {
+			return
+		}
+		projectStage := bson.D{
+			{"$project", bson.D{
+				{"_id", 0},
+				{"hours", bson.D{{"$cond", bson.A{
+					"$responded",
+					bson.D{{"$divide", bson.A{
+						bson.D{{"$subtract", bson.A{"$first_response.at", "$applied_on"}}},
+						3600000,
+					}}},
+					nil,
+				}}}},
+			}},
+		}
+
+		cursor, err := applicationCollection.Aggregate(ctx, append(pipeline, projectStage))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing response time")
+			return
+		}
+		var rows []struct {
+			Hours *float64 `bson:"hours"`
+		}
+		if err := cursor.All(ctx, &rows); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing response time")
+			return
+		}
+
+		report := models.ResponseTimeReport{Applied: len(rows)}
+		hours := []float64{}
+		for _, row := range rows {
+			if row.Hours == nil {
+				continue
+			}
+			report.Responded++
+			// A reply dated before the application means applied_at was
+			// entered wrong, so it says nothing about response time.
+			if *row.Hours >= 0 {
+				hours = append(hours, *row.Hours)
+			}
+		}
+		if median, ok := utils.Median(hours); ok {
+			report.MedianHours = &median
+		}
+
+		returnResponse(c, http.StatusOK, report)
+	}
+}
+
+// GetResponseRateBySource reports the reply rate for each application
+// source. Sources are compared case-insensitively.
+func GetResponseRateBySource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		pipeline, ok := applicationAnalyticsStages(ctx, c)
+		if !ok {
+			return
+		}
+		source := bson.D{{"$toLower", bson.D{{"$trim", bson.D{{"input", bson.D{{"$ifNull", bson.A{"$source", ""}}}}}}}}}
+		sortStage := bson.D{{"$sort", bson.D{{"applications", -1}, {"_id", 1}}}}
+
+		cursor, err := applicationCollection.Aggregate(ctx, append(pipeline, responseRateGroup(source), sortStage))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing response rates")
+			return
+		}
+		sources := []models.SourceResponseRate{}
+		if err := cursor.All(ctx, &sources); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing response rates")
+			return
+		}
+		for i := range sources {
+			sources[i].Rate = utils.Rate(sources[i].Responses, sources[i].Applications)
+		}
+
+		returnResponse(c, http.StatusOK, sources)
+	}
+}
+
+// GetResponseRateByResume compares the reply rate of each resume variant,
+// best first, so the user can see which tailored version works.
+func GetResponseRateByResume() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		pipeline, ok := applicationAnalyticsStages(ctx, c)
+		if !ok {
+			return
+		}
+		variant := bson.D{{"resume_id", "$resume_id"}, {"document_id", "$document_id"}}
+		resumeLookup := bson.D{{"$lookup", bson.D{
+			{"from", "resume"},
+			{"localField", "_id.resume_id"},
+			{"foreignField", "_id"},
+			{"as", "resume"},
+		}}}
+		documentLookup := bson.D{{"$lookup", bson.D{
+			{"from", "document"},
+			{"localField", "_id.document_id"},
+			{"foreignField", "_id"},
+			{"as", "document"},
+		}}}
+		projectStage := bson.D{
+			{"$project", bson.D{
+				{"_id", 0},
+				{"resume_id", "$_id.resume_id"},
+				{"document_id", "$_id.document_id"},
+				{"locale", bson.D{{"$arrayElemAt", bson.A{"$resume.locale", 0}}}},
+				{"file_name", bson.D{{"$arrayElemAt", bson.A{"$document.file_name", 0}}}},
+				{"applications", 1},
+				{"responses", 1},
+				{"interviews", 1},
+			}},
+		}
+
+		cursor, err := applicationCollection.Aggregate(ctx, append(pipeline,
+			responseRateGroup(variant), resumeLookup, documentLookup, projectStage))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing resume results")
+			return
+		}
+		variants := []models.ResumeVariantResult{}
+		if err := cursor.All(ctx, &variants); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while computing resume results")
+			return
+		}
+
+		for i := range variants {
+			variants[i].Rate = utils.Rate(variants[i].Responses, variants[i].Applications)
+		}
+		sort.SliceStable(variants, func(i, j int) bool {
+			if variants[i].Rate != variants[j].Rate {
+				return variants[i].Rate > variants[j].Rate
+			}
+			return variants[i].Applications > variants[j].Applications
+		})
+
+		returnResponse(c, http.StatusOK, variants)
+	}
+}
@@ -0,0 +1,422 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crafter/models"
+	"crafter/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const maxImportSize = 5 << 20
+
+// importPreviewRows is how many parsed rows a preview shows. The counts
+// in the preview still cover the whole file.
+const importPreviewRows = 20
+
+// importValidationFields maps the struct fields validate reports to the
+// import fields they come from.
+var importValidationFields = map[string]models.ImportField{
+	"CompanyName": models.ImportCompanyName,
+	"RoleTitle":   models.ImportRoleTitle,
+	"Status":      models.ImportStatus,
+	"Source":      models.ImportSource,
+	"Location":    models.ImportLocation,
+	"JobURL":      models.ImportJobURL,
+	"Min":         models.ImportSalaryMin,
+	"Max":         models.ImportSalaryMax,
+	"Currency":    models.ImportSalaryCurrency,
+	"Period":      models.ImportSalaryPeriod,
+}
+
+type importUpload struct {
+	headers  []string
+	records  []utils.CSVRecord
+	options  models.ImportOptions
+	dayFirst bool
+}
+
+// readImportUpload reads the uploaded CSV and the import options sent with
+// it. Without a mapping the suggested one is used.
+func readImportUpload(c *gin.Context) (importUpload, bool) {
+	var upload importUpload
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			returnError(c, http.StatusRequestEntityTooLarge, "file is larger than 5 MB")
+			return upload, false
+		}
+		returnError(c, http.StatusBadRequest, "file is required")
+		return upload, false
+	}
+	if header.Size > maxImportSize {
+		returnError(c, http.StatusRequestEntityTooLarge, "file is larger than 5 MB")
+		return upload, false
+	}
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv", ".tsv", ".txt":
+	default:
+		returnError(c, http.StatusUnsupportedMediaType, "only .csv, .tsv and .txt files can be imported")
+		return upload, false
+	}
+
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &upload.options); err != nil {
+			returnError(c, http.StatusBadRequest, "invalid options: "+err.Error())
+			return upload, false
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "error reading uploaded file")
+		return upload, false
+	}
+	defer file.Close()
+
+	upload.headers, upload.records, err = utils.ReadCSV(file)
+	if err != nil {
+		returnError(c, http.StatusUnprocessableEntity, err.Error())
+		return upload, false
+	}
+
+	if upload.options.Mapping == nil {
+		upload.options.Mapping = utils.SuggestImportMapping(upload.headers)
+	}
+	if err := utils.CheckImportMapping(upload.options.Mapping, upload.headers); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return upload, false
+	}
+
+	if upload.options.DayFirst != nil {
+		upload.dayFirst = *upload.options.DayFirst
+	} else {
+		dates := []string{}
+		for _, field := range []models.ImportField{models.ImportAppliedAt, models.ImportOfferDeadline} {
+			for i, header := range upload.headers {
+				if header != upload.options.Mapping[field] || upload.options.Mapping[field] == "" {
+					continue
+				}
+				for _, record := range upload.records {
+					if i < len(record.Fields) {
+						dates = append(dates, record.Fields[i])
+					}
+				}
+				break
+			}
+		}
+		upload.dayFirst, _ = utils.DetectDayFirst(dates)
+	}
+	return upload, true
+}
+
+func importValidationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "url":
+		return "is not a valid URL"
+	case "max":
+		return "is longer than " + fieldError.Param() + " characters"
+	case "len":
+		return "must be " + fieldError.Param() + " characters"
+	case "gtefield":
+		return "is lower than the minimum"
+	default:
+		return "is not valid (" + fieldError.Tag() + ")"
+	}
+}
+
+// parseImportRows parses and validates every row and marks the rows that
+// match an existing application or an earlier row of the file.
+func parseImportRows(ctx context.Context, userId primitive.ObjectID, upload importUpload) ([]models.ImportRow, error) {
+	opts := options.Find().SetProjection(bson.M{"company_name": 1, "role_title": 1})
+	cursor, err := applicationCollection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var existing []models.JobApplication
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	existingKeys := map[string]primitive.ObjectID{}
+	for _, application := range existing {
+		existingKeys[utils.ApplicationDuplicateKey(application.CompanyName, application.RoleTitle)] = application.ID
+	}
+
+	rows := []models.ImportRow{}
+	seen := map[string]int{}
+	for _, record := range upload.records {
+		row := utils.ParseImportRecord(upload.headers, record, upload.options.Mapping, upload.dayFirst)
+
+		reported := map[models.ImportField]bool{}
+		for _, rowError := range row.Errors {
+			reported[rowError.Field] = true
+		}
+		var validationErrors validator.ValidationErrors
+		if err := validate.Struct(row.Application); errors.As(err, &validationErrors) {
+			for _, fieldError := range validationErrors {
+				field, ok := importValidationFields[fieldError.StructField()]
+				if !ok {
+					field = models.ImportField(strings.ToLower(fieldError.StructField()))
+				}
+				if reported[field] {
+					continue
+				}
+				reported[field] = true
+				row.Errors = append(row.Errors, models.ImportRowError{
+					Row:     row.Row,
+					Field:   field,
+					Column:  upload.options.Mapping[field],
+					Message: fmt.Sprintf("%s %s", field, importValidationMessage(fieldError)),
+				})
+			}
+		}
+		if len(row.Note) > 20000 {
+			row.Errors = append(row.Errors, models.ImportRowError{
+				Row:     row.Row,
+				Field:   models.ImportNotes,
+				Column:  upload.options.Mapping[models.ImportNotes],
+				Message: "notes is longer than 20000 characters",
+			})
+		}
+
+		if row.Application.CompanyName != "" && row.Application.RoleTitle != "" {
+			key := utils.ApplicationDuplicateKey(row.Application.CompanyName, row.Application.RoleTitle)
+			if id, ok := existingKeys[key]; ok {
+				row.DuplicateOf = &id
+			} else if earlier, ok := seen[key]; ok {
+				row.DuplicateOfRow = earlier
+			} else {
+				seen[key] = row.Row
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// PreviewApplicationImport parses an uploaded CSV without saving anything.
+// It returns the suggested or given column mapping, the first rows as they
+// would be imported and the errors and duplicates found in the whole file.
+func PreviewApplicationImport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		upload, ok := readImportUpload(c)
+		if !ok {
+			return
+		}
+		rows, err := parseImportRows(ctx, userId, upload)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while checking for duplicates")
+			return
+		}
+
+		mapped := map[string]bool{}
+		for _, column := range upload.options.Mapping {
+			mapped[column] = true
+		}
+		preview := models.ImportPreview{
+			Headers:   upload.headers,
+			Mapping:   upload.options.Mapping,
+			Unmapped:  []string{},
+			DayFirst:  upload.dayFirst,
+			TotalRows: len(rows),
+			Rows:      rows,
+		}
+		for _, header := range upload.headers {
+			if !mapped[header] {
+				preview.Unmapped = append(preview.Unmapped, header)
+			}
+		}
+		for _, row := range rows {
+			switch {
+			case len(row.Errors) > 0:
+				preview.ErrorRows++
+			case row.DuplicateOf != nil || row.DuplicateOfRow != 0:
+				preview.DuplicateRows++
+			default:
+				preview.ValidRows++
+			}
+		}
+		if len(preview.Rows) > importPreviewRows {
+			preview.Rows = preview.Rows[:importPreviewRows]
+		}
+
+		returnResponse(c, http.StatusOK, preview)
+	}
+}
+
+// ImportApplications creates an application for every valid row of an
+// uploaded CSV. Rows with errors are reported and skipped, as are
+// duplicates unless import_duplicates is set.
+func ImportApplications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		upload, ok := readImportUpload(c)
+		if !ok {
+			return
+		}
+		if missing := utils.MissingImportFields(upload.options.Mapping); len(missing) > 0 {
+			returnError(c, http.StatusBadRequest, "a column must be mapped to "+strings.Join(missing, " and "))
+			return
+		}
+		rows, err := parseImportRows(ctx, userId, upload)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while checking for duplicates")
+			return
+		}
+
+		report := models.ImportReport{
+			TotalRows:   len(rows),
+			ImportedIDs: []primitive.ObjectID{},
+			Errors:      []models.ImportRowError{},
+		}
+		now := time.Now()
+		positions := map[models.ApplicationStatus]float64{}
+		applications := []interface{}{}
+		notes := []interface{}{}
+		for _, row := range rows {
+			if len(row.Errors) > 0 {
+				report.Failed++
+				report.Errors = append(report.Errors, row.Errors...)
+				continue
+			}
+			if (row.DuplicateOf != nil || row.DuplicateOfRow != 0) && !upload.options.ImportDuplicates {
+				report.SkippedDuplicates++
+				continue
+			}
+
+			application := row.Application
+			application.ID = primitive.NewObjectID()
+			application.UserID = userId
+			at := now
+			if application.AppliedAt != nil {
+				at = *application.AppliedAt
+			}
+			application.StatusHistory = []models.StatusChange{{To: application.Status, At: at, Note: "imported from CSV"}}
+
+			// Imported cards go to the end of their columns in file order.
+			if _, ok := positions[application.Status]; !ok {
+				if positions[application.Status], err = nextBoardPosition(ctx, userId, application.Status); err != nil {
+					returnError(c, http.StatusInternalServerError, "error occurred while placing applications on the board")
+					return
+				}
+			} else {
+				positions[application.Status] += utils.BoardPositionGap
+			}
+			application.BoardPosition = positions[application.Status]
+			application.CreatedAt = now
+			application.UpdatedAt = now
+			applications = append(applications, application)
+			report.ImportedIDs = append(report.ImportedIDs, application.ID)
+
+			if row.Note != "" {
+				notes = append(notes, models.ApplicationNote{
+					ID:            primitive.NewObjectID(),
+					UserID:        userId,
+					ApplicationID: application.ID,
+					Body:          row.Note,
+					Revisions:     []models.NoteRevision{},
+					CreatedAt:     now,
+					UpdatedAt:     now,
+				})
+			}
+		}
+
+		if len(applications) > 0 {
+			if _, err := applicationCollection.InsertMany(ctx, applications); err != nil {
+				returnError(c, http.StatusInternalServerError, "applications were not imported")
+				return
+			}
+		}
+		if len(notes) > 0 {
+			if _, err := noteCollection.InsertMany(ctx, notes); err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while importing notes")
+				return
+			}
+		}
+		report.Imported = len(applications)
+
+		returnResponse(c, http.StatusOK, report)
+	}
+}
+
+// ExportApplications downloads the user's applications as CSV with the
+// columns of models.ApplicationCSVColumns. The list filters apply.
+func ExportApplications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		query, ok := listQuery(ctx, c, userId, models.ApplicationTarget)
+		if !ok {
+			return
+		}
+		filter, err := applicationFilter(query, userId)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{"created_at", 1}, {"_id", 1}}).
+			SetProjection(bson.M{"resume_snapshot": 0, "interviews": 0, "status_history": 0})
+		cursor, err := applicationCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+		var applications []models.JobApplication
+		if err := cursor.All(ctx, &applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+
+		var body bytes.Buffer
+		if err := utils.WriteApplicationsCSV(&body, applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while writing CSV")
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="applications.csv"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+	}
+}
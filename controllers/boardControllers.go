@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// boardCard is the part of an application needed to place a card.
+type boardCard struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	BoardPosition float64            `bson:"board_position"`
+}
+
+// boardColumnCards returns the cards of one column in board order, leaving
+// out the application being moved.
+func boardColumnCards(ctx context.Context, userId primitive.ObjectID, status models.ApplicationStatus, exclude primitive.ObjectID) ([]boardCard, error) {
+	opts := options.Find().
+		SetSort(bson.D{{"board_position", 1}, {"created_at", 1}}).
+		SetProjection(bson.M{"board_position": 1})
+	cursor, err := applicationCollection.Find(ctx, bson.M{"user_id": userId, "status": status, "_id": bson.M{"$ne": exclude}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	cards := []boardCard{}
+	err = cursor.All(ctx, &cards)
+	return cards, err
+}
+
+// nextBoardPosition returns a position at the bottom of a column.
+func nextBoardPosition(ctx context.Context, userId primitive.ObjectID, status models.ApplicationStatus) (float64, error) {
+	var last boardCard
+	opts := options.FindOne().SetSort(bson.D{{"board_position", -1}}).SetProjection(bson.M{"board_position": 1})
+	err := applicationCollection.FindOne(ctx, bson.M{"user_id": userId, "status": status}, opts).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return utils.BoardPositionGap, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.BoardPosition + utils.BoardPositionGap, nil
+}
+
+// renumberBoardColumn rewrites the positions of a column whose gaps have
+// run out. The order of the cards does not change.
+func renumberBoardColumn(ctx context.Context, cards []boardCard) error {
+	positions := utils.RenumberBoardPositions(len(cards))
+	writes := []mongo.WriteModel{}
+	for i := range cards {
+		cards[i].BoardPosition = positions[i]
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": cards[i].ID}).
+			SetUpdate(bson.M{"$set": bson.M{"board_position": positions[i]}}))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err := applicationCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func GetApplicationBoard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		query, ok := listQuery(ctx, c, userId, models.ApplicationTarget)
+		if !ok {
+			return
+		}
+		filter, err := applicationFilter(query, userId)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		perColumn := 50
+		if limit, err := strconv.Atoi(c.Query("per_column")); err == nil && limit > 0 {
+			perColumn = limit
+		}
+
+		matchStage := bson.D{{"$match", filter}}
+		projectStage := bson.D{{"$project", bson.D{{"resume_snapshot", 0}, {"status_history", 0}}}}
+		sortStage := bson.D{{"$sort", bson.D{{"board_position", 1}, {"created_at", 1}}}}
+		groupStage := bson.D{{"$group", bson.D{
+			{"_id", "$status"},
+			{"count", bson.D{{"$sum", 1}}},
+			{"applications", bson.D{{"$push", "$$ROOT"}}},
+		}}}
+		sliceStage := bson.D{{"$project", bson.D{
+			{"count", 1},
+			{"applications", bson.D{{"$slice", bson.A{"$applications", perColumn}}}},
+		}}}
+
+		cursor, err := applicationCollection.Aggregate(ctx, mongo.Pipeline{matchStage, projectStage, sortStage, groupStage, sliceStage})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while building board")
+			return
+		}
+		var groups []struct {
+			Status       models.ApplicationStatus `bson:"_id"`
+			Count        int                      `bson:"count"`
+			Applications []models.JobApplication  `bson:"applications"`
+		}
+		if err := cursor.All(ctx, &groups); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while building board")
+			return
+		}
+
+		// Every status gets a column, in pipeline order, even when it is empty.
+		columns := map[models.ApplicationStatus]models.BoardColumn{}
+		for _, group := range groups {
+			columns[group.Status] = models.BoardColumn{Status: group.Status, Count: group.Count, Applications: group.Applications}
+		}
+		board := models.Board{Columns: []models.BoardColumn{}}
+		for _, status := range models.ApplicationStatuses {
+			column, ok := columns[status]
+			if !ok {
+				column = models.BoardColumn{Status: status, Applications: []models.JobApplication{}}
+			}
+			board.TotalCount += column.Count
+			board.Columns = append(board.Columns, column)
+		}
+
+		returnResponse(c, http.StatusOK, board)
+	}
+}
+
+func MoveApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var request models.BoardMoveRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		// Moving to another column is a status change and follows the same
+		// rules as the transitions endpoint.
+		from := application.Status
+		update := bson.M{}
+		if request.Status != from {
+			change, err := utils.TransitionApplication(&application, request.Status, request.Note, time.Now())
+			if err != nil {
+				returnError(c, http.StatusConflict, err.Error())
+				return
+			}
+			update["$push"] = bson.M{"status_history": change}
+		}
+
+		cards, err := boardColumnCards(ctx, application.UserID, application.Status, application.ID)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading board column")
+			return
+		}
+		positions := make([]float64, len(cards))
+		for i, card := range cards {
+			positions[i] = card.BoardPosition
+		}
+		position, ok := utils.BoardPosition(positions, request.Index)
+		if !ok {
+			if err := renumberBoardColumn(ctx, cards); err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while renumbering board column")
+				return
+			}
+			position, _ = utils.BoardPosition(utils.RenumberBoardPositions(len(cards)), request.Index)
+		}
+		application.BoardPosition = position
+		application.UpdatedAt = time.Now()
+
+		set := bson.M{"status": application.Status, "board_position": application.BoardPosition, "updated_at": application.UpdatedAt}
+		if application.AppliedAt != nil {
+			set["applied_at"] = application.AppliedAt
+		}
+		update["$set"] = set
+
+		// Stage and position are written in one update, guarded on the old
+		// status so a concurrent transition is not overwritten.
+		result, err := applicationCollection.UpdateOne(ctx, bson.M{"_id": application.ID, "status": from}, update)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while moving application")
+			return
+		}
+		if result.MatchedCount == 0 {
+			returnError(c, http.StatusConflict, "application status was changed by another request")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, application)
+	}
+}
@@ -0,0 +1,463 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var contactCollection *mongo.Collection = database.OpenCollection(database.Client, "contact")
+var interactionCollection *mongo.Collection = database.OpenCollection(database.Client, "contact_interaction")
+
+// defaultStaleDays is how long without contact makes a contact stale.
+const defaultStaleDays = 30
+
+func findUserContact(ctx context.Context, c *gin.Context) (models.Contact, bool) {
+	var contact models.Contact
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return contact, false
+	}
+	contactId, err := primitive.ObjectIDFromHex(c.Param("contact_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid contact_id")
+		return contact, false
+	}
+
+	err = contactCollection.FindOne(ctx, bson.M{"_id": contactId, "user_id": userId}).Decode(&contact)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "contact not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving contact")
+		}
+		return contact, false
+	}
+	return contact, true
+}
+
+// checkContactApplications makes sure every linked application belongs to
+// the user and drops duplicates.
+func checkContactApplications(ctx context.Context, c *gin.Context, userId primitive.ObjectID, contact *models.Contact) bool {
+	ids := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range contact.ApplicationIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	contact.ApplicationIDs = ids
+	if len(ids) == 0 {
+		return true
+	}
+
+	count, err := applicationCollection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}, "user_id": userId})
+	if err != nil {
+		returnError(c, http.StatusInternalServerError, "error occurred while retrieving applications")
+		return false
+	}
+	if int(count) != len(ids) {
+		returnError(c, http.StatusBadRequest, "application not found")
+		return false
+	}
+	return true
+}
+
+func CreateContact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var contact models.Contact
+		if err := c.BindJSON(&contact); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		contact.Tags = utils.NormalizeTags(contact.Tags)
+		if validationErr := validate.Struct(contact); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if !checkContactApplications(ctx, c, userId, &contact) {
+			return
+		}
+
+		contact.ID = primitive.NewObjectID()
+		contact.UserID = userId
+		contact.CreatedAt = time.Now()
+		contact.UpdatedAt = time.Now()
+
+		if _, err := contactCollection.InsertOne(ctx, contact); err != nil {
+			returnError(c, http.StatusInternalServerError, "contact was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, contact)
+	}
+}
+
+// contactFilter builds the contact list filter from the company,
+// application_id and tags query parameters.
+func contactFilter(query url.Values, userId primitive.ObjectID) (bson.M, error) {
+	filter := bson.M{"user_id": userId}
+	if company := query.Get("company"); company != "" {
+		filter["company"] = bson.M{"$regex": regexp.QuoteMeta(company), "$options": "i"}
+	}
+	if applicationId := query.Get("application_id"); applicationId != "" {
+		id, err := primitive.ObjectIDFromHex(applicationId)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid application_id")
+		}
+		filter["application_ids"] = id
+	}
+	tagFilter(filter, query)
+	return filter, nil
+}
+
+// GetContacts lists a user's contacts by name. It can be narrowed with
+// ?company=, ?application_id=, ?tags= and ?filter_id=.
+func GetContacts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		query, ok := listQuery(ctx, c, userId, models.ContactTarget)
+		if !ok {
+			return
+		}
+		filter, err := contactFilter(query, userId)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		cursor, err := contactCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{"name", 1}}))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing contacts")
+			return
+		}
+		contacts := []models.Contact{}
+		if err := cursor.All(ctx, &contacts); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing contacts")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, contacts)
+	}
+}
+
+// GetStaleContacts lists the contacts the user has not been in touch with
+// for ?days= days (30 by default), including those never contacted at all,
+// longest silence first.
+func GetStaleContacts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		days := defaultStaleDays
+		if value := c.Query("days"); value != "" {
+			if days, err = strconv.Atoi(value); err != nil || days < 1 {
+				returnError(c, http.StatusBadRequest, "days must be a positive number")
+				return
+			}
+		}
+		cutoff := time.Now().AddDate(0, 0, -days)
+
+		query, ok := listQuery(ctx, c, userId, models.ContactTarget)
+		if !ok {
+			return
+		}
+		filter, err := contactFilter(query, userId)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter["$or"] = bson.A{
+			bson.M{"last_contacted_at": nil},
+			bson.M{"last_contacted_at": bson.M{"$lt": cutoff}},
+		}
+		// Missing dates sort first, so contacts never reached come first.
+		opts := options.Find().SetSort(bson.D{{"last_contacted_at", 1}, {"name", 1}})
+		cursor, err := contactCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing contacts")
+			return
+		}
+		contacts := []models.Contact{}
+		if err := cursor.All(ctx, &contacts); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing contacts")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"days": days, "contacts": contacts})
+	}
+}
+
+func GetContact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, contact)
+	}
+}
+
+func UpdateContact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+
+		var contact models.Contact
+		if err := c.BindJSON(&contact); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		contact.Tags = utils.NormalizeTags(contact.Tags)
+		if validationErr := validate.Struct(contact); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if !checkContactApplications(ctx, c, existing.UserID, &contact) {
+			return
+		}
+
+		contact.ID = existing.ID
+		contact.UserID = existing.UserID
+		if contact.Tags == nil {
+			contact.Tags = existing.Tags
+		}
+		contact.CreatedAt = existing.CreatedAt
+		contact.UpdatedAt = time.Now()
+		// Logged interactions keep counting even if the update sends an
+		// older date.
+		if existing.LastContactedAt != nil && (contact.LastContactedAt == nil || contact.LastContactedAt.Before(*existing.LastContactedAt)) {
+			contact.LastContactedAt = existing.LastContactedAt
+		}
+
+		if _, err := contactCollection.ReplaceOne(ctx, bson.M{"_id": existing.ID}, contact); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating contact")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, contact)
+	}
+}
+
+func DeleteContact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+
+		if _, err := contactCollection.DeleteOne(ctx, bson.M{"_id": contact.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting contact")
+			return
+		}
+		if _, err := interactionCollection.DeleteMany(ctx, bson.M{"contact_id": contact.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting contact interactions")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "contact deleted successfully"})
+	}
+}
+
+func LinkContactApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var updated models.Contact
+		err := contactCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": contact.ID},
+			bson.M{
+				"$addToSet": bson.M{"application_ids": application.ID},
+				"$set":      bson.M{"updated_at": time.Now()},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while linking contact")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, updated)
+	}
+}
+
+func UnlinkContactApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+		applicationId, err := primitive.ObjectIDFromHex(c.Param("application_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid application_id")
+			return
+		}
+
+		var updated models.Contact
+		err = contactCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": contact.ID},
+			bson.M{
+				"$pull": bson.M{"application_ids": applicationId},
+				"$set":  bson.M{"updated_at": time.Now()},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while unlinking contact")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, updated)
+	}
+}
+
+func CreateInteraction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+
+		var interaction models.Interaction
+		if err := c.BindJSON(&interaction); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(interaction); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if interaction.OccurredAt.IsZero() {
+			interaction.OccurredAt = time.Now()
+		}
+		if interaction.OccurredAt.After(time.Now()) {
+			returnError(c, http.StatusBadRequest, "an interaction cannot be dated in the future")
+			return
+		}
+		if interaction.ApplicationID != nil {
+			count, err := applicationCollection.CountDocuments(ctx, bson.M{"_id": *interaction.ApplicationID, "user_id": contact.UserID})
+			if err != nil || count == 0 {
+				returnError(c, http.StatusBadRequest, "application not found")
+				return
+			}
+		}
+
+		interaction.ID = primitive.NewObjectID()
+		interaction.UserID = contact.UserID
+		interaction.ContactID = contact.ID
+		interaction.CreatedAt = time.Now()
+
+		if _, err := interactionCollection.InsertOne(ctx, interaction); err != nil {
+			returnError(c, http.StatusInternalServerError, "interaction was not created")
+			return
+		}
+
+		// $max keeps the latest date when older interactions are logged late.
+		update := bson.M{
+			"$max": bson.M{"last_contacted_at": interaction.OccurredAt},
+			"$set": bson.M{"updated_at": time.Now()},
+		}
+		if interaction.ApplicationID != nil {
+			update["$addToSet"] = bson.M{"application_ids": *interaction.ApplicationID}
+		}
+		if _, err := contactCollection.UpdateOne(ctx, bson.M{"_id": contact.ID}, update); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating contact")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, interaction)
+	}
+}
+
+func GetInteractions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		contact, ok := findUserContact(ctx, c)
+		if !ok {
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{"occurred_at", -1}})
+		cursor, err := interactionCollection.Find(ctx, bson.M{"contact_id": contact.ID}, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing interactions")
+			return
+		}
+		interactions := []models.Interaction{}
+		if err := cursor.All(ctx, &interactions); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing interactions")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, interactions)
+	}
+}
@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var coverLetterCollection *mongo.Collection = database.OpenCollection(database.Client, "cover_letter")
+
+func findUserCoverLetter(ctx context.Context, c *gin.Context) (models.CoverLetter, bool) {
+	var letter models.CoverLetter
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return letter, false
+	}
+	letterId, err := primitive.ObjectIDFromHex(c.Param("cover_letter_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid cover_letter_id")
+		return letter, false
+	}
+
+	err = coverLetterCollection.FindOne(ctx, bson.M{"_id": letterId, "user_id": userId}).Decode(&letter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "cover letter not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving cover letter")
+		}
+		return letter, false
+	}
+	return letter, true
+}
+
+// checkUserResume makes sure a linked resume belongs to the user.
+func checkUserResume(ctx context.Context, c *gin.Context, userId primitive.ObjectID, resumeId *primitive.ObjectID) (*models.Resume, bool) {
+	if resumeId == nil {
+		return nil, true
+	}
+
+	var resume models.Resume
+	err := resumeCollection.FindOne(ctx, bson.M{"_id": *resumeId, "user_id": userId}).Decode(&resume)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusBadRequest, "resume not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving resume")
+		}
+		return nil, false
+	}
+	return &resume, true
+}
+
+func GetCoverLetterTemplates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		names := []string{}
+		for name := range utils.CoverLetterTemplates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"templates": names,
+			"default":   utils.DefaultCoverLetterTemplate,
+		})
+	}
+}
+
+func CreateCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var letter models.CoverLetter
+		if err := c.BindJSON(&letter); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(letter); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if _, ok := checkUserResume(ctx, c, userId, letter.ResumeID); !ok {
+			return
+		}
+
+		letter.ID = primitive.NewObjectID()
+		letter.UserID = userId
+		letter.CreatedAt = time.Now()
+		letter.UpdatedAt = time.Now()
+
+		if _, err := coverLetterCollection.InsertOne(ctx, letter); err != nil {
+			returnError(c, http.StatusInternalServerError, "cover letter was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, letter)
+	}
+}
+
+func GenerateCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var request models.GenerateCoverLetterRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		templateText := request.TemplateText
+		if templateText == "" {
+			if request.Template == "" {
+				request.Template = utils.DefaultCoverLetterTemplate
+			}
+			var ok bool
+			if templateText, ok = utils.CoverLetterTemplates[request.Template]; !ok {
+				returnError(c, http.StatusBadRequest, "unknown template "+request.Template)
+				return
+			}
+		}
+
+		var user models.User
+		err = userCollection.FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "user not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving user")
+			}
+			return
+		}
+
+		resume, ok := checkUserResume(ctx, c, userId, request.ResumeID)
+		if !ok {
+			return
+		}
+
+		fields := utils.BuildCoverLetterMergeFields(user, resume, request.TargetJob, time.Now())
+		body, err := utils.GenerateCoverLetter(templateText, fields)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		letter := models.CoverLetter{
+			ID:        primitive.NewObjectID(),
+			UserID:    userId,
+			ResumeID:  request.ResumeID,
+			TargetJob: request.TargetJob,
+			Title:     request.Title,
+			Template:  request.Template,
+			Body:      body,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if letter.Title == "" {
+			letter.Title = "Cover letter"
+			if fields.TargetCompany != "" {
+				letter.Title += " for " + fields.TargetCompany
+			}
+		}
+
+		if validationErr := validate.Struct(letter); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		if _, err := coverLetterCollection.InsertOne(ctx, letter); err != nil {
+			returnError(c, http.StatusInternalServerError, "cover letter was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, letter)
+	}
+}
+
+func GetCoverLetters() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{"updated_at", -1}})
+		cursor, err := coverLetterCollection.Find(ctx, bson.M{"user_id": userId}, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing cover letters")
+			return
+		}
+		letters := []models.CoverLetter{}
+		if err := cursor.All(ctx, &letters); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing cover letters")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, letters)
+	}
+}
+
+func GetCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		letter, ok := findUserCoverLetter(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, letter)
+	}
+}
+
+func UpdateCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, ok := findUserCoverLetter(ctx, c)
+		if !ok {
+			return
+		}
+
+		var letter models.CoverLetter
+		if err := c.BindJSON(&letter); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(letter); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if _, ok := checkUserResume(ctx, c, existing.UserID, letter.ResumeID); !ok {
+			return
+		}
+
+		letter.ID = existing.ID
+		letter.UserID = existing.UserID
+		letter.CreatedAt = existing.CreatedAt
+		letter.UpdatedAt = time.Now()
+
+		if _, err := coverLetterCollection.ReplaceOne(ctx, bson.M{"_id": existing.ID}, letter); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating cover letter")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, letter)
+	}
+}
+
+func DeleteCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		letter, ok := findUserCoverLetter(ctx, c)
+		if !ok {
+			return
+		}
+
+		if _, err := coverLetterCollection.DeleteOne(ctx, bson.M{"_id": letter.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting cover letter")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "cover letter deleted successfully"})
+	}
+}
+
+// ExportCoverLetter writes a cover letter with the same redaction profiles
+// as resume exports, so a letter cannot give away what its resume hides.
+func ExportCoverLetter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		profile := models.RedactionProfile(c.DefaultQuery("profile", string(models.FullProfile)))
+		if _, ok := models.RedactionProfiles[profile]; !ok {
+			returnError(c, http.StatusBadRequest, "unknown redaction profile "+string(profile))
+			return
+		}
+
+		letter, ok := findUserCoverLetter(ctx, c)
+		if !ok {
+			return
+		}
+
+		// A deleted resume only means there is no location to redact.
+		var resume *models.Resume
+		if letter.ResumeID != nil {
+			var linked models.Resume
+			err := resumeCollection.FindOne(ctx, bson.M{"_id": *letter.ResumeID, "user_id": letter.UserID}).Decode(&linked)
+			if err == nil {
+				resume = &linked
+			} else if err != mongo.ErrNoDocuments {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving resume")
+				return
+			}
+		}
+
+		redacted, err := utils.RedactCoverLetter(letter, profile, resume)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		body, contentType, err := utils.RenderCoverLetter(redacted, c.Query("format"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Data(http.StatusOK, contentType, body)
+	}
+}
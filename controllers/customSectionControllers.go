@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bindCustomSection binds and validates a custom section from the request
+// body. On failure the error response has already been written.
+func bindCustomSection(c *gin.Context) (models.CustomSection, bool) {
+	var section models.CustomSection
+	if err := c.BindJSON(&section); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return section, false
+	}
+
+	if validationErr := validate.Struct(section); validationErr != nil {
+		returnError(c, http.StatusBadRequest, validationErr.Error())
+		return section, false
+	}
+
+	if err := utils.ValidateCustomSection(section); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return section, false
+	}
+
+	if section.Entries == nil {
+		section.Entries = []models.CustomEntry{}
+	}
+	// Entries sent back keep their IDs so layouts hiding them still apply.
+	utils.AssignCustomEntryIDs(&section)
+	return section, true
+}
+
+func findCustomSection(c *gin.Context, resume models.Resume) (int, bool) {
+	sectionId, err := primitive.ObjectIDFromHex(c.Param("section_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid section_id")
+		return -1, false
+	}
+
+	for i, section := range resume.CustomSections {
+		if section.ID == sectionId {
+			return i, true
+		}
+	}
+
+	returnError(c, http.StatusNotFound, "section not found")
+	return -1, false
+}
+
+func GetCustomSections() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, utils.SortedCustomSections(resume.CustomSections))
+	}
+}
+
+func GetCustomSection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findCustomSection(c, resume)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, resume.CustomSections[index])
+	}
+}
+
+func CreateCustomSection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		section, ok := bindCustomSection(c)
+		if !ok {
+			return
+		}
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		// New sections go to the end of the resume.
+		section.ID = primitive.NewObjectID()
+		section.Position = utils.NextCustomSectionPosition(resume.CustomSections)
+
+		_, err := resumeCollection.UpdateOne(ctx, bson.M{"_id": resume.ID}, bson.M{
+			"$push": bson.M{"custom_sections": section},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "section was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, section)
+	}
+}
+
+func UpdateCustomSection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		section, ok := bindCustomSection(c)
+		if !ok {
+			return
+		}
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findCustomSection(c, resume)
+		if !ok {
+			return
+		}
+
+		// Position is only changed through the order endpoint.
+		section.ID = resume.CustomSections[index].ID
+		section.Position = resume.CustomSections[index].Position
+
+		_, err := resumeCollection.UpdateOne(ctx,
+			bson.M{"_id": resume.ID, "custom_sections._id": section.ID},
+			bson.M{"$set": bson.M{
+				"custom_sections.$": section,
+				"updated_at":        time.Now(),
+			}},
+		)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating section")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, section)
+	}
+}
+
+func DeleteCustomSection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findCustomSection(c, resume)
+		if !ok {
+			return
+		}
+
+		_, err := resumeCollection.UpdateOne(ctx, bson.M{"_id": resume.ID}, bson.M{
+			"$pull": bson.M{"custom_sections": bson.M{"_id": resume.CustomSections[index].ID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting section")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "section deleted successfully"})
+	}
+}
+
+func ReorderCustomSections() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var order models.CustomSectionOrder
+		if err := c.BindJSON(&order); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(order); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		sections, err := utils.ReorderCustomSections(resume.CustomSections, order.SectionIDs)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		_, err = resumeCollection.UpdateOne(ctx, bson.M{"_id": resume.ID}, bson.M{
+			"$set": bson.M{"custom_sections": sections, "updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reordering sections")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, utils.SortedCustomSections(sections))
+	}
+}
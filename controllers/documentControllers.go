@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/storage"
+	"crafter/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var documentCollection *mongo.Collection = database.OpenCollection(database.Client, "document")
+
+const maxDocumentSize = 10 << 20
+
+// documentType pairs the content type stored for an extension with the
+// prefix http.DetectContentType must report for the uploaded bytes.
+type documentType struct {
+	contentType string
+	sniffed     string
+}
+
+var allowedDocumentTypes = map[string]documentType{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".txt":  {"text/plain; charset=utf-8", "text/plain"},
+}
+
+func findUserDocument(ctx context.Context, c *gin.Context) (models.Document, bool) {
+	var document models.Document
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return document, false
+	}
+	documentId, err := primitive.ObjectIDFromHex(c.Param("document_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid document_id")
+		return document, false
+	}
+
+	err = documentCollection.FindOne(ctx, bson.M{"_id": documentId, "user_id": userId}).Decode(&document)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "document not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving document")
+		}
+		return document, false
+	}
+	return document, true
+}
+
+// deleteDocuments removes the documents matching filter together with their
+// stored files and the user's references to them.
+func deleteDocuments(ctx context.Context, filter bson.M) error {
+	cursor, err := documentCollection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var documents []models.Document
+	if err := cursor.All(ctx, &documents); err != nil {
+		return err
+	}
+
+	for _, document := range documents {
+		if document.StorageKey != "" {
+			if err := storage.Blobs.Delete(ctx, document.StorageKey); err != nil && err != storage.ErrNotFound {
+				return err
+			}
+		}
+		if _, err := documentCollection.DeleteOne(ctx, bson.M{"_id": document.ID}); err != nil {
+			return err
+		}
+		_, err := userCollection.UpdateOne(ctx, bson.M{"_id": document.UserID}, bson.M{
+			"$pull": bson.M{"resume_documents": document.ID},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = applicationCollection.UpdateMany(ctx, bson.M{"document_id": document.ID}, bson.M{
+			"$unset": bson.M{"document_id": ""},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateResumeURLs moves the links users kept in resume_urls before
+// uploads existed into link documents, so they show up next to uploaded
+// files. A link already migrated is reused, so an interrupted run can be
+// repeated.
+func MigrateResumeURLs(ctx context.Context) error {
+	cursor, err := userCollection.Find(ctx, bson.M{"resume_urls": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"resume_urls": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user struct {
+			ID         primitive.ObjectID `bson:"_id"`
+			ResumeURLs []string           `bson:"resume_urls"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		ids := []primitive.ObjectID{}
+		for _, link := range user.ResumeURLs {
+			document := utils.LinkDocument(user.ID, link, time.Now())
+			var stored models.Document
+			err := documentCollection.FindOneAndUpdate(ctx,
+				bson.M{"user_id": user.ID, "url": link},
+				bson.M{"$setOnInsert": document},
+				options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+			).Decode(&stored)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, stored.ID)
+		}
+
+		_, err := userCollection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{
+			"$addToSet": bson.M{"resume_documents": bson.M{"$each": ids}},
+			"$unset":    bson.M{"resume_urls": ""},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func UploadDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		// Leave some room for the multipart envelope around the file.
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDocumentSize+1<<20)
+
+		header, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				returnError(c, http.StatusRequestEntityTooLarge, "file is larger than 10 MB")
+				return
+			}
+			returnError(c, http.StatusBadRequest, "file is required")
+			return
+		}
+		if header.Size > maxDocumentSize {
+			returnError(c, http.StatusRequestEntityTooLarge, "file is larger than 10 MB")
+			return
+		}
+		if header.Size == 0 {
+			returnError(c, http.StatusBadRequest, "file is empty")
+			return
+		}
+
+		fileType, ok := allowedDocumentTypes[strings.ToLower(filepath.Ext(header.Filename))]
+		if !ok {
+			returnError(c, http.StatusUnsupportedMediaType, "only .pdf, .docx and .txt files can be uploaded")
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "error reading uploaded file")
+			return
+		}
+		defer file.Close()
+
+		// Check the bytes, not just the name, and hash the file in the same pass.
+		sniff := make([]byte, 512)
+		n, _ := io.ReadFull(file, sniff)
+		if !strings.HasPrefix(http.DetectContentType(sniff[:n]), fileType.sniffed) {
+			returnError(c, http.StatusUnsupportedMediaType, "file content does not match its extension")
+			return
+		}
+		hash := sha256.New()
+		hash.Write(sniff[:n])
+		if _, err := io.Copy(hash, file); err != nil {
+			returnError(c, http.StatusBadRequest, "error reading uploaded file")
+			return
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			returnError(c, http.StatusInternalServerError, "error reading uploaded file")
+			return
+		}
+
+		document := models.Document{
+			ID:          primitive.NewObjectID(),
+			UserID:      userId,
+			FileName:    filepath.Base(header.Filename),
+			ContentType: fileType.contentType,
+			Size:        header.Size,
+			SHA256:      hex.EncodeToString(hash.Sum(nil)),
+			Description: c.PostForm("description"),
+			CreatedAt:   time.Now(),
+		}
+		document.StorageKey = "users/" + userId.Hex() + "/documents/" + document.ID.Hex()
+
+		if resumeId := c.PostForm("resume_id"); resumeId != "" {
+			id, err := primitive.ObjectIDFromHex(resumeId)
+			if err != nil {
+				returnError(c, http.StatusBadRequest, "Invalid resume_id")
+				return
+			}
+			count, err := resumeCollection.CountDocuments(ctx, bson.M{"_id": id, "user_id": userId})
+			if err != nil || count == 0 {
+				returnError(c, http.StatusBadRequest, "resume not found")
+				return
+			}
+			document.ResumeID = &id
+		}
+
+		if applicationId := c.PostForm("application_id"); applicationId != "" {
+			id, err := primitive.ObjectIDFromHex(applicationId)
+			if err != nil {
+				returnError(c, http.StatusBadRequest, "Invalid application_id")
+				return
+			}
+			count, err := applicationCollection.CountDocuments(ctx, bson.M{"_id": id, "user_id": userId})
+			if err != nil || count == 0 {
+				returnError(c, http.StatusBadRequest, "application not found")
+				return
+			}
+			document.ApplicationID = &id
+		}
+
+		if err := storage.Blobs.Put(ctx, document.StorageKey, file, document.Size, document.ContentType); err != nil {
+			returnError(c, http.StatusInternalServerError, "error storing uploaded file")
+			return
+		}
+
+		if _, err := documentCollection.InsertOne(ctx, document); err != nil {
+			storage.Blobs.Delete(ctx, document.StorageKey)
+			returnError(c, http.StatusInternalServerError, "document was not created")
+			return
+		}
+
+		_, err = userCollection.UpdateOne(ctx, bson.M{"_id": userId}, bson.M{
+			"$addToSet": bson.M{"resume_documents": document.ID},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating user")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, document)
+	}
+}
+
+func GetDocuments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		cursor, err := documentCollection.Find(ctx, bson.M{"user_id": userId})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing documents")
+			return
+		}
+		documents := []models.Document{}
+		if err := cursor.All(ctx, &documents); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing documents")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, documents)
+	}
+}
+
+func GetDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		document, ok := findUserDocument(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, document)
+	}
+}
+
+func DownloadDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		document, ok := findUserDocument(ctx, c)
+		if !ok {
+			return
+		}
+
+		if document.URL != "" {
+			c.Redirect(http.StatusFound, document.URL)
+			return
+		}
+
+		body, err := storage.Blobs.Get(ctx, document.StorageKey)
+		if err != nil {
+			if err == storage.ErrNotFound {
+				returnError(c, http.StatusNotFound, "document file not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while reading document")
+			}
+			return
+		}
+		defer body.Close()
+
+		c.DataFromReader(http.StatusOK, document.Size, document.ContentType, body, map[string]string{
+			"Content-Disposition": `attachment; filename="` + strings.ReplaceAll(document.FileName, `"`, "") + `"`,
+			"X-Content-Sha256":    document.SHA256,
+		})
+	}
+}
+
+func DeleteDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		document, ok := findUserDocument(ctx, c)
+		if !ok {
+			return
+		}
+
+		if err := deleteDocuments(ctx, bson.M{"_id": document.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting document")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "document deleted successfully"})
+	}
+}
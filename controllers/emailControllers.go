@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var emailProposalCollection *mongo.Collection = database.OpenCollection(database.Client, "email_proposal")
+
+// maxEmailUploadSize is larger than other uploads because an mbox export
+// of a few months of job hunting easily runs to several megabytes.
+const maxEmailUploadSize = 25 << 20
+
+func findUserProposal(ctx context.Context, c *gin.Context) (models.EmailProposal, bool) {
+	var proposal models.EmailProposal
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return proposal, false
+	}
+	proposalId, err := primitive.ObjectIDFromHex(c.Param("proposal_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid proposal_id")
+		return proposal, false
+	}
+
+	err = emailProposalCollection.FindOne(ctx, bson.M{"_id": proposalId, "user_id": userId}).Decode(&proposal)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "proposal not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving proposal")
+		}
+		return proposal, false
+	}
+	return proposal, true
+}
+
+// ImportEmails reads an uploaded .eml file or mbox archive and proposes a
+// status change for every message about one of the user's applications.
+// Messages seen in an earlier upload are skipped.
+func ImportEmails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailUploadSize+1<<20)
+
+		header, err := c.FormFile("file")
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "file is required")
+			return
+		}
+		if header.Size > maxEmailUploadSize {
+			returnError(c, http.StatusRequestEntityTooLarge, "file is larger than 25 MB")
+			return
+		}
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".eml", ".mbox", ".mbx":
+		default:
+			returnError(c, http.StatusUnsupportedMediaType, "only .eml and .mbox files can be imported")
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "error reading uploaded file")
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "error reading uploaded file")
+			return
+		}
+
+		messages, problems := utils.ParseEmails(data)
+		if len(messages) == 0 && len(problems) > 0 {
+			returnError(c, http.StatusUnprocessableEntity, "no email could be read: "+problems[0])
+			return
+		}
+
+		opts := options.Find().SetProjection(bson.M{"resume_snapshot": 0, "interviews": 0, "status_history": 0})
+		cursor, err := applicationCollection.Find(ctx, bson.M{"user_id": userId}, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+		var applications []models.JobApplication
+		if err := cursor.All(ctx, &applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+
+		messageIds := []string{}
+		for _, message := range messages {
+			messageIds = append(messageIds, message.MessageID)
+		}
+		cursor, err = emailProposalCollection.Find(ctx,
+			bson.M{"user_id": userId, "message_id": bson.M{"$in": messageIds}},
+			options.Find().SetProjection(bson.M{"message_id": 1}))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while checking earlier imports")
+			return
+		}
+		var earlier []models.EmailProposal
+		if err := cursor.All(ctx, &earlier); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while checking earlier imports")
+			return
+		}
+		seen := map[string]bool{}
+		for _, proposal := range earlier {
+			seen[proposal.MessageID] = true
+		}
+
+		report := models.EmailImportReport{
+			Messages:  len(messages),
+			Errors:    problems,
+			Proposals: []models.EmailProposal{},
+		}
+		documents := []interface{}{}
+		for _, message := range messages {
+			kind, target, cues := utils.ClassifyEmail(message)
+			if kind == models.UnknownEmail {
+				report.Unclassified++
+				continue
+			}
+			if seen[message.MessageID] {
+				report.Duplicate++
+				continue
+			}
+			seen[message.MessageID] = true
+
+			proposal := models.EmailProposal{
+				ID:             primitive.NewObjectID(),
+				UserID:         userId,
+				MessageID:      message.MessageID,
+				From:           message.From,
+				FromName:       message.FromName,
+				Subject:        message.Subject,
+				ReceivedAt:     message.Date,
+				Kind:           kind,
+				Cues:           cues,
+				ProposedStatus: target,
+				Status:         models.ProposalPending,
+				CreatedAt:      time.Now(),
+			}
+			match := utils.MatchEmailApplication(message, applications)
+			proposal.MatchReasons = match.Reasons
+			if match.Application != nil {
+				// Mail about a step the application is already past, like a
+				// late confirmation, needs no action.
+				if _, ok := utils.TransitionPath(match.Application.Status, target); !ok {
+					report.UpToDate++
+					continue
+				}
+				proposal.ApplicationID = &match.Application.ID
+			} else {
+				report.Unmatched++
+				for _, candidate := range match.Candidates {
+					proposal.Candidates = append(proposal.Candidates, candidate.ID)
+				}
+			}
+
+			documents = append(documents, proposal)
+			report.Proposals = append(report.Proposals, proposal)
+		}
+
+		if len(documents) > 0 {
+			if _, err := emailProposalCollection.InsertMany(ctx, documents); err != nil {
+				returnError(c, http.StatusInternalServerError, "proposals were not saved")
+				return
+			}
+		}
+
+		returnResponse(c, http.StatusOK, report)
+	}
+}
+
+// GetEmailProposals lists proposals, newest email first. Only pending ones
+// are listed unless ?status= asks for confirmed or dismissed ones.
+func GetEmailProposals() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		status := models.ProposalStatus(c.DefaultQuery("status", string(models.ProposalPending)))
+		switch status {
+		case models.ProposalPending, models.ProposalConfirmed, models.ProposalDismissed:
+		default:
+			returnError(c, http.StatusBadRequest, "status must be pending, confirmed or dismissed")
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{"received_at", -1}})
+		cursor, err := emailProposalCollection.Find(ctx, bson.M{"user_id": userId, "status": status}, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing proposals")
+			return
+		}
+		proposals := []models.EmailProposal{}
+		if err := cursor.All(ctx, &proposals); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing proposals")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, proposals)
+	}
+}
+
+// ConfirmEmailProposal applies a proposed status change. A saved
+// application that gets a rejection or an interview invite is moved
+// through applied on the way, dated like the email.
+func ConfirmEmailProposal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		proposal, ok := findUserProposal(ctx, c)
+		if !ok {
+			return
+		}
+		if proposal.Status != models.ProposalPending {
+			returnError(c, http.StatusConflict, "proposal was already "+string(proposal.Status))
+			return
+		}
+
+		var request models.ConfirmProposalRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		applicationId := proposal.ApplicationID
+		if request.ApplicationID != nil {
+			applicationId = request.ApplicationID
+		}
+		if applicationId == nil {
+			returnError(c, http.StatusBadRequest, "application_id is required for an email that matched no application")
+			return
+		}
+
+		var application models.JobApplication
+		err := applicationCollection.FindOne(ctx, bson.M{"_id": *applicationId, "user_id": proposal.UserID}).Decode(&application)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "application not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving application")
+			}
+			return
+		}
+
+		path, ok := utils.TransitionPath(application.Status, proposal.ProposedStatus)
+		if !ok {
+			returnError(c, http.StatusConflict, "cannot move an application from "+string(application.Status)+" to "+string(proposal.ProposedStatus))
+			return
+		}
+
+		// An email older than the last status change is recorded now, so the
+		// history stays in order.
+		at := proposal.ReceivedAt
+		if at.IsZero() || at.After(time.Now()) || at.Before(utils.LastStatusChange(application)) {
+			at = time.Now()
+		}
+		note := request.Note
+		if note == "" {
+			note = "From email: " + proposal.Subject
+		}
+
+		from := application.Status
+		changes := []models.StatusChange{}
+		for _, status := range path {
+			change, err := utils.TransitionApplication(&application, status, note, at)
+			if err != nil {
+				returnError(c, http.StatusConflict, err.Error())
+				return
+			}
+			changes = append(changes, change)
+		}
+		if !storeTransition(ctx, c, &application, from, changes) {
+			return
+		}
+
+		resolvedAt := time.Now()
+		proposal.Status = models.ProposalConfirmed
+		proposal.ApplicationID = &application.ID
+		proposal.ResolvedAt = &resolvedAt
+		_, err = emailProposalCollection.UpdateOne(ctx, bson.M{"_id": proposal.ID}, bson.M{"$set": bson.M{
+			"status":         proposal.Status,
+			"application_id": proposal.ApplicationID,
+			"resolved_at":    proposal.ResolvedAt,
+		}})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating proposal")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"proposal": proposal, "application": application})
+	}
+}
+
+func DismissEmailProposal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		proposalId, err := primitive.ObjectIDFromHex(c.Param("proposal_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid proposal_id")
+			return
+		}
+
+		var proposal models.EmailProposal
+		err = emailProposalCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": proposalId, "user_id": userId, "status": models.ProposalPending},
+			bson.M{"$set": bson.M{"status": models.ProposalDismissed, "resolved_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&proposal)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "pending proposal not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while dismissing proposal")
+			}
+			return
+		}
+
+		returnResponse(c, http.StatusOK, proposal)
+	}
+}
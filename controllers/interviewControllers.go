@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var calendarFeedCollection *mongo.Collection = database.OpenCollection(database.Client, "calendar_feed")
+
+// calendarFeedHistory is how far back the subscribable feed reaches.
+const calendarFeedHistory = 180 * 24 * time.Hour
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// bindInterview binds and validates an interview round from the request
+// body. On failure the error response has already been written.
+func bindInterview(c *gin.Context) (models.Interview, bool) {
+	var interview models.Interview
+	if err := c.BindJSON(&interview); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return interview, false
+	}
+
+	if validationErr := validate.Struct(interview); validationErr != nil {
+		returnError(c, http.StatusBadRequest, validationErr.Error())
+		return interview, false
+	}
+
+	if err := utils.ValidateInterview(&interview); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return interview, false
+	}
+
+	if interview.Interviewers == nil {
+		interview.Interviewers = []models.Interviewer{}
+	}
+	return interview, true
+}
+
+func findInterview(c *gin.Context, application models.JobApplication) (int, bool) {
+	interviewId, err := primitive.ObjectIDFromHex(c.Param("interview_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid interview_id")
+		return -1, false
+	}
+
+	for i, interview := range application.Interviews {
+		if interview.ID == interviewId {
+			return i, true
+		}
+	}
+
+	returnError(c, http.StatusNotFound, "interview not found")
+	return -1, false
+}
+
+// calendarFileName turns a title into a safe .ics attachment name.
+func calendarFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, title)
+	return strings.Trim(name, "-") + ".ics"
+}
+
+func GetInterviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		interviews := application.Interviews
+		if interviews == nil {
+			interviews = []models.Interview{}
+		}
+		returnResponse(c, http.StatusOK, interviews)
+	}
+}
+
+func CreateInterview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		interview, ok := bindInterview(c)
+		if !ok {
+			return
+		}
+		interview.ID = primitive.NewObjectID()
+		interview.Sequence = 0
+		interview.UpdatedAt = time.Now()
+
+		_, err := applicationCollection.UpdateOne(ctx, bson.M{"_id": application.ID}, bson.M{
+			"$push": bson.M{"interviews": interview},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "interview was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, interview)
+	}
+}
+
+func UpdateInterview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findInterview(c, application)
+		if !ok {
+			return
+		}
+
+		interview, ok := bindInterview(c)
+		if !ok {
+			return
+		}
+		interview.ID = application.Interviews[index].ID
+		interview.Sequence = application.Interviews[index].Sequence + 1
+		interview.UpdatedAt = time.Now()
+
+		_, err := applicationCollection.UpdateOne(ctx,
+			bson.M{"_id": application.ID, "interviews._id": interview.ID},
+			bson.M{"$set": bson.M{
+				"interviews.$": interview,
+				"updated_at":   time.Now(),
+			}},
+		)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating interview")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, interview)
+	}
+}
+
+func DeleteInterview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findInterview(c, application)
+		if !ok {
+			return
+		}
+
+		_, err := applicationCollection.UpdateOne(ctx, bson.M{"_id": application.ID}, bson.M{
+			"$pull": bson.M{"interviews": bson.M{"_id": application.Interviews[index].ID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting interview")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "interview deleted successfully"})
+	}
+}
+
+func ExportInterview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		index, ok := findInterview(c, application)
+		if !ok {
+			return
+		}
+
+		interview := application.Interviews[index]
+		application.Interviews = []models.Interview{interview}
+		title := interview.Round + " " + application.CompanyName
+
+		c.Header("Content-Disposition", `attachment; filename="`+calendarFileName(title)+`"`)
+		c.Data(http.StatusOK, calendarContentType, utils.RenderInterviewCalendar(title, []models.JobApplication{application}))
+	}
+}
+
+// CreateCalendarFeed issues a new secret feed token for the user. Any
+// earlier token stops working.
+func CreateCalendarFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		token := make([]byte, 24)
+		if _, err := rand.Read(token); err != nil {
+			returnError(c, http.StatusInternalServerError, "error generating feed token")
+			return
+		}
+
+		feed := models.CalendarFeed{
+			ID:        primitive.NewObjectID(),
+			UserID:    userId,
+			Token:     hex.EncodeToString(token),
+			CreatedAt: time.Now(),
+		}
+
+		if _, err := calendarFeedCollection.DeleteMany(ctx, bson.M{"user_id": userId}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while replacing calendar feed")
+			return
+		}
+		if _, err := calendarFeedCollection.InsertOne(ctx, feed); err != nil {
+			returnError(c, http.StatusInternalServerError, "calendar feed was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"feed": feed,
+			"path": "/shared/calendar/" + feed.Token,
+		})
+	}
+}
+
+func DeleteCalendarFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		if _, err := calendarFeedCollection.DeleteMany(ctx, bson.M{"user_id": userId}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting calendar feed")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "calendar feed deleted successfully"})
+	}
+}
+
+// GetCalendarFeed serves a user's interviews to calendar apps. The token in
+// the URL is the only credential, since calendar apps cannot log in.
+func GetCalendarFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var feed models.CalendarFeed
+		err := calendarFeedCollection.FindOne(ctx, bson.M{"token": c.Param("token")}).Decode(&feed)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "calendar feed not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving calendar feed")
+			}
+			return
+		}
+
+		filter := bson.M{
+			"user_id":              feed.UserID,
+			"interviews.starts_at": bson.M{"$gte": time.Now().Add(-calendarFeedHistory)},
+		}
+		opts := options.Find().SetProjection(bson.M{"company_name": 1, "role_title": 1, "job_url": 1, "interviews": 1})
+		cursor, err := applicationCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while building calendar feed")
+			return
+		}
+		applications := []models.JobApplication{}
+		if err := cursor.All(ctx, &applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while building calendar feed")
+			return
+		}
+
+		c.Header("Cache-Control", "private, max-age=900")
+		c.Data(http.StatusOK, calendarContentType, utils.RenderInterviewCalendar("Interviews", applications))
+	}
+}
@@ -0,0 +1,482 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var applicationCollection *mongo.Collection = database.OpenCollection(database.Client, "job_application")
+
+func findUserApplication(ctx context.Context, c *gin.Context) (models.JobApplication, bool) {
+	var application models.JobApplication
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return application, false
+	}
+	applicationId, err := primitive.ObjectIDFromHex(c.Param("application_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid application_id")
+		return application, false
+	}
+
+	err = applicationCollection.FindOne(ctx, bson.M{"_id": applicationId, "user_id": userId}).Decode(&application)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "application not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving application")
+		}
+		return application, false
+	}
+	return application, true
+}
+
+// linkApplicationResume checks that the resume and document referenced by an
+// application belong to the user and freezes a copy of the resume.
+func linkApplicationResume(ctx context.Context, c *gin.Context, application *models.JobApplication) bool {
+	application.ResumeSnapshot = nil
+	if application.ResumeID != nil {
+		var resume models.Resume
+		err := resumeCollection.FindOne(ctx, bson.M{"_id": *application.ResumeID, "user_id": application.UserID}).Decode(&resume)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusBadRequest, "resume not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving resume")
+			}
+			return false
+		}
+		application.ResumeSnapshot = &resume
+	}
+
+	if application.DocumentID != nil {
+		count, err := documentCollection.CountDocuments(ctx, bson.M{"_id": *application.DocumentID, "user_id": application.UserID})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving document")
+			return false
+		}
+		if count == 0 {
+			returnError(c, http.StatusBadRequest, "document not found")
+			return false
+		}
+	}
+	return true
+}
+
+// parseFilterDate accepts a plain date, an RFC 3339 timestamp or one of
+// the relative dates of utils.RelativeDate.
+func parseFilterDate(value string) (time.Time, error) {
+	if t, ok := utils.RelativeDate(value, time.Now()); ok {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// applicationFilter builds the list filter from the status, company,
+// applied_from, applied_to and tags query parameters.
+func applicationFilter(query url.Values, userId primitive.ObjectID) (bson.M, error) {
+	filter := bson.M{"user_id": userId}
+
+	if status := query.Get("status"); status != "" {
+		filter["status"] = bson.M{"$in": strings.Split(status, ",")}
+	}
+
+	if company := query.Get("company"); company != "" {
+		filter["company_name"] = bson.M{"$regex": regexp.QuoteMeta(company), "$options": "i"}
+	}
+
+	applied := bson.M{}
+	if from := query.Get("applied_from"); from != "" {
+		t, err := parseFilterDate(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid applied_from date")
+		}
+		applied["$gte"] = t
+	}
+	if to := query.Get("applied_to"); to != "" {
+		t, err := parseFilterDate(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid applied_to date")
+		}
+		if !strings.Contains(to, "T") {
+			// A plain date includes the whole day.
+			t = t.Add(24 * time.Hour)
+		}
+		applied["$lt"] = t
+	}
+	if len(applied) > 0 {
+		filter["applied_at"] = applied
+	}
+
+	tagFilter(filter, query)
+	return filter, nil
+}
+
+func CreateApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var application models.JobApplication
+		if err := c.BindJSON(&application); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		application.Tags = utils.NormalizeTags(application.Tags)
+		if application.Status == "" {
+			application.Status = models.StatusSaved
+		}
+		if validationErr := validate.Struct(application); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if !utils.CanStartIn(application.Status) {
+			returnError(c, http.StatusBadRequest, "a new application must start as saved or applied")
+			return
+		}
+
+		application.ID = primitive.NewObjectID()
+		application.UserID = userId
+		if err := utils.StartApplication(&application, time.Now()); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !linkApplicationResume(ctx, c, &application) {
+			return
+		}
+		application.Interviews = nil
+		application.Offer = nil
+		if application.BoardPosition, err = nextBoardPosition(ctx, userId, application.Status); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while placing application on the board")
+			return
+		}
+		application.CreatedAt = time.Now()
+		application.UpdatedAt = time.Now()
+
+		if _, err := applicationCollection.InsertOne(ctx, application); err != nil {
+			returnError(c, http.StatusInternalServerError, "application was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, application)
+	}
+}
+
+func GetApplications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		query, ok := listQuery(ctx, c, userId, models.ApplicationTarget)
+		if !ok {
+			return
+		}
+		filter, err := applicationFilter(query, userId)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		recordPerPage := 10
+		page := 1
+		if rpp, err := strconv.Atoi(c.Query("recordPerPage")); err == nil && rpp > 0 {
+			recordPerPage = rpp
+		}
+		if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+			page = p
+		}
+
+		totalCount, err := applicationCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while counting applications")
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{"applied_at", -1}, {"created_at", -1}}).
+			SetSkip(int64((page - 1) * recordPerPage)).
+			SetLimit(int64(recordPerPage)).
+			SetProjection(bson.M{"resume_snapshot": 0})
+		cursor, err := applicationCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+		applications := []models.JobApplication{}
+		if err := cursor.All(ctx, &applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing applications")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"total_count":   totalCount,
+			"applications":  applications,
+			"page":          page,
+			"recordPerPage": recordPerPage,
+		})
+	}
+}
+
+func GetApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, application)
+	}
+}
+
+func UpdateApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var application models.JobApplication
+		if err := c.BindJSON(&application); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		application.Tags = utils.NormalizeTags(application.Tags)
+		if application.Status == "" {
+			application.Status = existing.Status
+		}
+		if application.Status != existing.Status {
+			returnError(c, http.StatusBadRequest, "status can only be changed through the transitions endpoint")
+			return
+		}
+		if validationErr := validate.Struct(application); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		application.UserID = existing.UserID
+
+		// Keep the snapshot of what was sent unless a different resume is linked.
+		sameResume := application.ResumeID != nil && existing.ResumeID != nil && *application.ResumeID == *existing.ResumeID
+		if sameResume {
+			snapshot := existing.ResumeSnapshot
+			if !linkApplicationResume(ctx, c, &application) {
+				return
+			}
+			application.ResumeSnapshot = snapshot
+		} else if !linkApplicationResume(ctx, c, &application) {
+			return
+		}
+
+		// Only the fields this endpoint edits are written, so a status
+		// transition, interview or offer saved meanwhile is not overwritten.
+		set := bson.M{
+			"company_name":    application.CompanyName,
+			"role_title":      application.RoleTitle,
+			"job_url":         application.JobURL,
+			"location":        application.Location,
+			"salary":          application.Salary,
+			"source":          application.Source,
+			"applied_at":      application.AppliedAt,
+			"resume_id":       application.ResumeID,
+			"document_id":     application.DocumentID,
+			"resume_snapshot": application.ResumeSnapshot,
+			"offer_deadline":  application.OfferDeadline,
+			"updated_at":      time.Now(),
+		}
+		if application.Tags != nil {
+			set["tags"] = application.Tags
+		}
+
+		var updated models.JobApplication
+		err := applicationCollection.FindOneAndUpdate(ctx, bson.M{"_id": existing.ID}, bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "application not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while updating application")
+			}
+			return
+		}
+
+		returnResponse(c, http.StatusOK, updated)
+	}
+}
+
+func DeleteApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		if _, err := applicationCollection.DeleteOne(ctx, bson.M{"_id": application.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting application")
+			return
+		}
+
+		_, err := contactCollection.UpdateMany(ctx, bson.M{"user_id": application.UserID, "application_ids": application.ID}, bson.M{
+			"$pull": bson.M{"application_ids": application.ID},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while unlinking contacts")
+			return
+		}
+		if _, err := noteCollection.DeleteMany(ctx, bson.M{"application_id": application.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting application notes")
+			return
+		}
+		// Pending email proposals for the application go back to unmatched
+		// so the user can point them at another one.
+		_, err = emailProposalCollection.UpdateMany(ctx,
+			bson.M{"application_id": application.ID, "status": models.ProposalPending},
+			bson.M{"$unset": bson.M{"application_id": ""}},
+		)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while unlinking email proposals")
+			return
+		}
+		_, err = interactionCollection.UpdateMany(ctx,
+			bson.M{"application_id": application.ID},
+			bson.M{"$unset": bson.M{"application_id": ""}},
+		)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while unlinking contact interactions")
+			return
+		}
+		if _, err := reminderCollection.DeleteMany(ctx, bson.M{"application_id": application.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting application reminders")
+			return
+		}
+		if err := deleteDocuments(ctx, bson.M{"user_id": application.UserID, "application_id": application.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting application documents")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "application deleted successfully"})
+	}
+}
+
+func GetApplicationStatuses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		returnResponse(c, http.StatusOK, models.ApplicationTransitions)
+	}
+}
+
+func TransitionApplication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var request models.StatusTransitionRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		at := time.Now()
+		if request.At != nil {
+			if request.At.After(at) {
+				returnError(c, http.StatusBadRequest, "a transition cannot be dated in the future")
+				return
+			}
+			at = *request.At
+		}
+
+		from := application.Status
+		change, err := utils.TransitionApplication(&application, request.Status, request.Note, at)
+		if err != nil {
+			returnError(c, http.StatusConflict, err.Error())
+			return
+		}
+		if !storeTransition(ctx, c, &application, from, []models.StatusChange{change}) {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, application)
+	}
+}
+
+// storeTransition saves status changes already applied to the application
+// in memory. It writes the error response itself when it fails.
+func storeTransition(ctx context.Context, c *gin.Context, application *models.JobApplication, from models.ApplicationStatus, changes []models.StatusChange) bool {
+	var err error
+
+	// The application joins the bottom of its new board column.
+	if application.BoardPosition, err = nextBoardPosition(ctx, application.UserID, application.Status); err != nil {
+		returnError(c, http.StatusInternalServerError, "error occurred while placing application on the board")
+		return false
+	}
+
+	set := bson.M{"status": application.Status, "board_position": application.BoardPosition, "updated_at": time.Now()}
+	if application.AppliedAt != nil {
+		set["applied_at"] = application.AppliedAt
+	}
+
+	// Matching on the old status makes concurrent transitions fail
+	// instead of silently overwriting each other.
+	result, err := applicationCollection.UpdateOne(ctx,
+		bson.M{"_id": application.ID, "status": from},
+		bson.M{"$set": set, "$push": bson.M{"status_history": bson.M{"$each": changes}}},
+	)
+	if err != nil {
+		returnError(c, http.StatusInternalServerError, "error occurred while updating application status")
+		return false
+	}
+	if result.MatchedCount == 0 {
+		returnError(c, http.StatusConflict, "application status was changed by another request")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var jobFitCollection *mongo.Collection = database.OpenCollection(database.Client, "job_fit")
+
+// fitIsCurrent reports whether a stored fit was computed from the job,
+// resume and experience level as they are now.
+func fitIsCurrent(fit models.JobFit, job models.SavedJob, resume models.Resume, experience models.ExperienceLevel) bool {
+	return fit.JobUpdatedAt.Equal(job.UpdatedAt) &&
+		fit.ResumeUpdatedAt.Equal(resume.UpdatedAt) &&
+		fit.ExperienceLevel == experience
+}
+
+// RankSavedJobs ranks the user's saved jobs by how well they fit the resume
+// given by ?resume_id=. Scores are stored and only recomputed for jobs
+// whose posting, resume or experience level changed since.
+func RankSavedJobs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		resumeId, err := primitive.ObjectIDFromHex(c.Query("resume_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "resume_id is required")
+			return
+		}
+
+		var user models.User
+		err = userCollection.FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "user not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving user")
+			}
+			return
+		}
+
+		resume, ok := checkUserResume(ctx, c, userId, &resumeId)
+		if !ok {
+			return
+		}
+
+		cursor, err := savedJobCollection.Find(ctx, bson.M{"user_id": userId})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved jobs")
+			return
+		}
+		jobs := []models.SavedJob{}
+		if err := cursor.All(ctx, &jobs); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved jobs")
+			return
+		}
+
+		cursor, err = jobFitCollection.Find(ctx, bson.M{"user_id": userId, "resume_id": resumeId})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing job fits")
+			return
+		}
+		stored := []models.JobFit{}
+		if err := cursor.All(ctx, &stored); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing job fits")
+			return
+		}
+		storedByJob := map[primitive.ObjectID]models.JobFit{}
+		for _, fit := range stored {
+			storedByJob[fit.JobID] = fit
+		}
+
+		now := time.Now()
+		fits := map[string]models.JobFit{}
+		writes := []mongo.WriteModel{}
+		for _, job := range jobs {
+			if fit, ok := storedByJob[job.ID]; ok && fitIsCurrent(fit, job, *resume, user.Experience) {
+				fits[job.ID.Hex()] = fit
+				continue
+			}
+
+			fit := utils.ScoreJobFit(job, *resume, user.Experience, now)
+			fit.UserID = userId
+			fit.JobID = job.ID
+			fit.ResumeID = resumeId
+			fit.JobUpdatedAt = job.UpdatedAt
+			fit.ResumeUpdatedAt = resume.UpdatedAt
+			fit.ExperienceLevel = user.Experience
+			fit.ComputedAt = now
+			fits[job.ID.Hex()] = fit
+
+			writes = append(writes, mongo.NewReplaceOneModel().
+				SetFilter(bson.M{"job_id": job.ID, "resume_id": resumeId}).
+				SetReplacement(fit).
+				SetUpsert(true))
+		}
+		if len(writes) > 0 {
+			if _, err := jobFitCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while saving job fits")
+				return
+			}
+		}
+
+		// The ranking is for choosing what to apply to, so the long
+		// descriptions are left out like in the saved job list.
+		for i := range jobs {
+			jobs[i].Description = ""
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"resume_id":        resumeId,
+			"experience_level": user.Experience,
+			"recomputed":       len(writes),
+			"jobs":             utils.RankJobFits(jobs, fits),
+		})
+	}
+}
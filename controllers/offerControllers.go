@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func GetOffer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+		if application.Offer == nil {
+			returnError(c, http.StatusNotFound, "application has no offer")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, application.Offer)
+	}
+}
+
+// SetOffer records or replaces the offer of an application.
+func SetOffer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var offer models.Offer
+		if err := c.BindJSON(&offer); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(offer); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if err := utils.ValidateOffer(&offer); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		_, err := applicationCollection.UpdateOne(ctx, bson.M{"_id": application.ID}, bson.M{
+			"$set": bson.M{"offer": offer, "updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "offer was not saved")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, offer)
+	}
+}
+
+func DeleteOffer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+		if application.Offer == nil {
+			returnError(c, http.StatusNotFound, "application has no offer")
+			return
+		}
+
+		_, err := applicationCollection.UpdateOne(ctx, bson.M{"_id": application.ID}, bson.M{
+			"$unset": bson.M{"offer": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "offer was not deleted")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, "offer deleted")
+	}
+}
+
+// CompareOffers normalises offers to one currency so they can be compared
+// side by side. Every currency used by an offer other than the target one
+// needs a rate.
+func CompareOffers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var request models.OfferComparisonRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		filter := bson.M{"user_id": userId, "offer": bson.M{"$exists": true}}
+		if len(request.ApplicationIDs) > 0 {
+			filter["_id"] = bson.M{"$in": request.ApplicationIDs}
+		}
+		opts := options.Find().SetProjection(bson.M{"company_name": 1, "role_title": 1, "location": 1, "offer": 1})
+		cursor, err := applicationCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing offers")
+			return
+		}
+		var applications []models.JobApplication
+		if err := cursor.All(ctx, &applications); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing offers")
+			return
+		}
+		if len(request.ApplicationIDs) > 0 && len(applications) < len(request.ApplicationIDs) {
+			returnError(c, http.StatusNotFound, "some applications were not found or have no offer")
+			return
+		}
+
+		comparison, err := utils.CompareOffers(applications, request.Currency, request.Rates)
+		if err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		returnResponse(c, http.StatusOK, comparison)
+	}
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var reminderCollection *mongo.Collection = database.OpenCollection(database.Client, "reminder")
+
+// GetReminders lists a user's reminders, newest first. ?pending=true keeps
+// only the ones that have not been dismissed.
+func GetReminders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		filter := bson.M{"user_id": userId}
+		if c.Query("pending") == "true" {
+			filter["dismissed_at"] = nil
+		}
+		if applicationId := c.Query("application_id"); applicationId != "" {
+			id, err := primitive.ObjectIDFromHex(applicationId)
+			if err != nil {
+				returnError(c, http.StatusBadRequest, "Invalid application_id")
+				return
+			}
+			filter["application_id"] = id
+		}
+
+		cursor, err := reminderCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{"due_at", -1}}))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing reminders")
+			return
+		}
+		reminders := []models.Reminder{}
+		if err := cursor.All(ctx, &reminders); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing reminders")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, reminders)
+	}
+}
+
+// DismissReminder marks a reminder as handled. A reminder that has not been
+// delivered yet will not be sent.
+func DismissReminder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		reminderId, err := primitive.ObjectIDFromHex(c.Param("reminder_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid reminder_id")
+			return
+		}
+
+		var reminder models.Reminder
+		err = reminderCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": reminderId, "user_id": userId},
+			bson.M{"$set": bson.M{"dismissed_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&reminder)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "reminder not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while dismissing reminder")
+			}
+			return
+		}
+
+		returnResponse(c, http.StatusOK, reminder)
+	}
+}
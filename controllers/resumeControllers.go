@@ -0,0 +1,616 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var resumeCollection *mongo.Collection = database.OpenCollection(database.Client, "resume")
+var resumeShareCollection *mongo.Collection = database.OpenCollection(database.Client, "resume_share")
+
+// findUserResume loads the resume addressed by the :user_id and :resume_id
+// route parameters. On failure the error response has already been written.
+func findUserResume(ctx context.Context, c *gin.Context) (models.Resume, bool) {
+	return findUserResumeByParam(ctx, c, "resume_id")
+}
+
+func findUserResumeByParam(ctx context.Context, c *gin.Context, param string) (models.Resume, bool) {
+	var resume models.Resume
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return resume, false
+	}
+	resumeId, err := primitive.ObjectIDFromHex(c.Param(param))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid "+param)
+		return resume, false
+	}
+
+	err = resumeCollection.FindOne(ctx, bson.M{"_id": resumeId, "user_id": userId}).Decode(&resume)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "resume not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving resume")
+		}
+		return resume, false
+	}
+	return resume, true
+}
+
+// writeResumeExport redacts the resume with the given profile and writes it
+// in the requested format. Every path that hands a resume out goes through
+// here so the redaction rules cannot be skipped.
+func writeResumeExport(c *gin.Context, resume models.Resume, profile models.RedactionProfile, format string) {
+	redacted, err := utils.RedactResume(resume, profile)
+	if err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body, contentType, err := utils.RenderResume(redacted, format)
+	if err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func ExportResume() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		profile := models.RedactionProfile(c.DefaultQuery("profile", string(models.FullProfile)))
+		writeResumeExport(c, resume, profile, c.Query("format"))
+	}
+}
+
+func ShareResume() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		share := models.ResumeShare{Profile: models.PublicWebProfile}
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&share); err != nil {
+				returnError(c, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		if validationErr := validate.Struct(share); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		token := make([]byte, 24)
+		if _, err := rand.Read(token); err != nil {
+			returnError(c, http.StatusInternalServerError, "error generating share token")
+			return
+		}
+
+		share.ID = primitive.NewObjectID()
+		share.UserID = resume.UserID
+		share.ResumeID = resume.ID
+		share.Token = hex.EncodeToString(token)
+		share.CreatedAt = time.Now()
+
+		if _, err := resumeShareCollection.InsertOne(ctx, share); err != nil {
+			returnError(c, http.StatusInternalServerError, "resume share was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, share)
+	}
+}
+
+// DeleteResumeShare revokes a share link. The token stops working at once.
+func DeleteResumeShare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		resumeId, err := primitive.ObjectIDFromHex(c.Param("resume_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid resume_id")
+			return
+		}
+		shareId, err := primitive.ObjectIDFromHex(c.Param("share_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid share_id")
+			return
+		}
+
+		result, err := resumeShareCollection.DeleteOne(ctx, bson.M{"_id": shareId, "user_id": userId, "resume_id": resumeId})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting resume share")
+			return
+		}
+		if result.DeletedCount == 0 {
+			returnError(c, http.StatusNotFound, "resume share not found")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "resume share deleted successfully"})
+	}
+}
+
+func GetSharedResume() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var share models.ResumeShare
+		err := resumeShareCollection.FindOne(ctx, bson.M{"token": c.Param("token")}).Decode(&share)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "shared resume not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving shared resume")
+			}
+			return
+		}
+
+		var resume models.Resume
+		err = resumeCollection.FindOne(ctx, bson.M{"_id": share.ResumeID, "user_id": share.UserID}).Decode(&resume)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				returnError(c, http.StatusNotFound, "shared resume not found")
+			} else {
+				returnError(c, http.StatusInternalServerError, "error occurred while retrieving shared resume")
+			}
+			return
+		}
+
+		// The profile always comes from the share, never from the viewer.
+		writeResumeExport(c, resume, share.Profile, c.Query("format"))
+	}
+}
+
+func AnonymizeResume() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		var options models.AnonymizationOptions
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&options); err != nil {
+				returnError(c, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		// Entries hidden by the layout never leave the resume, so they are not
+		// part of the anonymized copy either.
+		returnResponse(c, http.StatusOK, utils.AnonymizeResume(utils.ApplyResumeLayout(resume), options))
+	}
+}
+
+// findLocaleSiblings returns the other translations of a resume.
+func findLocaleSiblings(ctx context.Context, resume models.Resume) ([]models.Resume, error) {
+	siblings := []models.Resume{}
+	if resume.LocaleGroupID.IsZero() {
+		return siblings, nil
+	}
+
+	cursor, err := resumeCollection.Find(ctx, bson.M{
+		"user_id":         resume.UserID,
+		"locale_group_id": resume.LocaleGroupID,
+		"_id":             bson.M{"$ne": resume.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &siblings); err != nil {
+		return nil, err
+	}
+	for i := range siblings {
+		if siblings[i].Locale == "" {
+			siblings[i].Locale = models.DefaultLocale
+		}
+	}
+	return siblings, nil
+}
+
+func CreateResumeLocale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		var request models.CreateLocaleRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		if resume.Locale == "" {
+			resume.Locale = models.DefaultLocale
+		}
+		if resume.LocaleGroupID.IsZero() {
+			// The first translation turns the original resume into the
+			// head of a locale group.
+			resume.LocaleGroupID = resume.ID
+			_, err := resumeCollection.UpdateOne(ctx, bson.M{"_id": resume.ID}, bson.M{
+				"$set": bson.M{"locale": resume.Locale, "locale_group_id": resume.LocaleGroupID},
+			})
+			if err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while updating resume")
+				return
+			}
+		}
+
+		siblings, err := findLocaleSiblings(ctx, resume)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing resume translations")
+			return
+		}
+		for _, sibling := range append(siblings, resume) {
+			if sibling.Locale == request.Locale {
+				returnError(c, http.StatusConflict, "a translation for this locale already exists")
+				return
+			}
+		}
+
+		// The new sibling starts as a copy of the source text so it can be
+		// translated in place while keeping the same structure.
+		translation := resume
+		translation.ID = primitive.NewObjectID()
+		translation.Locale = request.Locale
+		translation.CreatedAt = time.Now()
+		translation.UpdatedAt = time.Now()
+
+		if _, err := resumeCollection.InsertOne(ctx, translation); err != nil {
+			returnError(c, http.StatusInternalServerError, "resume translation was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, translation)
+	}
+}
+
+func GetResumeLocales() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		siblings, err := findLocaleSiblings(ctx, resume)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing resume translations")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, siblings)
+	}
+}
+
+func CheckResumeLocaleSync() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+		if resume.Locale == "" {
+			resume.Locale = models.DefaultLocale
+		}
+
+		siblings, err := findLocaleSiblings(ctx, resume)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing resume translations")
+			return
+		}
+
+		results := []gin.H{}
+		for _, sibling := range siblings {
+			results = append(results, gin.H{
+				"resume_id": sibling.ID,
+				"locale":    sibling.Locale,
+				"issues":    utils.CheckLocaleSync(resume, sibling),
+			})
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"resume_id": resume.ID,
+			"locale":    resume.Locale,
+			"siblings":  results,
+		})
+	}
+}
+
+func GetResumeLayout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		layout := models.ResumeLayout{}
+		if resume.Layout != nil {
+			layout = *resume.Layout
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"layout":        layout,
+			"section_order": utils.ResumeSectionOrder(resume),
+		})
+	}
+}
+
+func UpdateResumeLayout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		var layout models.ResumeLayout
+		if err := c.BindJSON(&layout); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := utils.ValidateResumeLayout(layout, resume); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		_, err := resumeCollection.UpdateOne(ctx, bson.M{"_id": resume.ID}, bson.M{
+			"$set": bson.M{"layout": layout, "updated_at": time.Now()},
+		})
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating resume layout")
+			return
+		}
+
+		resume.Layout = &layout
+		returnResponse(c, http.StatusOK, gin.H{
+			"layout":        layout,
+			"section_order": utils.ResumeSectionOrder(resume),
+		})
+	}
+}
+
+func CompareResumes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		base, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		target, ok := findUserResumeByParam(ctx, c, "other_resume_id")
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, utils.DiffResumes(base, target))
+	}
+}
+
+// GetResumes lists the user's resumes, most recently updated first. Like
+// the other lists it can be narrowed with ?tags= and ?filter_id=.
+func GetResumes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		query, ok := listQuery(ctx, c, userId, models.ResumeTarget)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{"user_id": userId}
+		tagFilter(filter, query)
+
+		cursor, err := resumeCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{"updated_at", -1}}))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing resumes")
+			return
+		}
+		resumes := []models.Resume{}
+		if err := cursor.All(ctx, &resumes); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing resumes")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, resumes)
+	}
+}
+
+// MigrateEntryIDs gives the entries of resumes saved before entries had IDs
+// an ID each, so layouts can refer to them. A resume that changes while it
+// is migrated is left for the next run, so a run can be repeated.
+func MigrateEntryIDs(ctx context.Context) error {
+	missing := bson.M{"$elemMatch": bson.M{"_id": bson.M{"$exists": false}}}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"work_experience": missing},
+		bson.M{"education": missing},
+		bson.M{"projects": missing},
+		bson.M{"certifications": missing},
+		bson.M{"honors_awards": missing},
+		bson.M{"extracurriculars": missing},
+		bson.M{"custom_sections.entries": missing},
+	}}
+
+	cursor, err := resumeCollection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var resume models.Resume
+		if err := cursor.Decode(&resume); err != nil {
+			return err
+		}
+		if !utils.AssignEntryIDs(&resume) {
+			continue
+		}
+
+		unchanged := bson.M{"_id": resume.ID, "updated_at": resume.UpdatedAt}
+		if resume.UpdatedAt.IsZero() {
+			// Old resumes may have no updated_at at all.
+			unchanged["updated_at"] = bson.M{"$in": bson.A{nil, resume.UpdatedAt}}
+		}
+		_, err := resumeCollection.UpdateOne(ctx, unchanged, bson.M{"$set": bson.M{
+			"work_experience":  resume.WorkExperience,
+			"education":        resume.Education,
+			"projects":         resume.Projects,
+			"certifications":   resume.Certifications,
+			"honors_awards":    resume.HonorsAwards,
+			"extracurriculars": resume.Extracurriculars,
+			"custom_sections":  resume.CustomSections,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// EnsureResumeSearchIndex creates the text index SearchResumes runs on. It
+// is led by user_id so a search only touches the caller's resumes.
+func EnsureResumeSearchIndex(ctx context.Context) error {
+	keys := bson.D{{"user_id", 1}}
+	for _, field := range utils.SearchIndexFields {
+		keys = append(keys, bson.E{field, "text"})
+	}
+	_, err := resumeCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName("resume_search").SetDefaultLanguage("none"),
+	})
+	return err
+}
+
+func SearchResumes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		query := c.Query("q")
+		if utils.SearchQuery(query) == "" {
+			returnError(c, http.StatusBadRequest, "q parameter is required")
+			return
+		}
+
+		section := c.Query("section")
+		if section != "" {
+			known := false
+			for _, s := range utils.SearchSections {
+				known = known || s == section
+			}
+			if !known {
+				returnError(c, http.StatusBadRequest, "unknown section "+section)
+				return
+			}
+		}
+
+		limit := 50
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+			limit = l
+		}
+
+		params, ok := listQuery(ctx, c, userId, models.ResumeTarget)
+		if !ok {
+			return
+		}
+		// The text index narrows the search down to the resumes holding every
+		// term; only those are scanned for the exact matches.
+		filter := bson.M{"user_id": userId, "$text": bson.M{"$search": utils.SearchQuery(query)}}
+		tagFilter(filter, params)
+
+		cursor, err := resumeCollection.Find(ctx, filter)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while searching resumes")
+			return
+		}
+		var resumes []models.Resume
+		if err := cursor.All(ctx, &resumes); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while searching resumes")
+			return
+		}
+
+		hits := utils.SearchResumes(resumes, query, section)
+		total := len(hits)
+		if len(hits) > limit {
+			hits = hits[:limit]
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"total_count": total,
+			"results":     hits,
+		})
+	}
+}
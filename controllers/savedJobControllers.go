@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var savedJobCollection *mongo.Collection = database.OpenCollection(database.Client, "saved_job")
+
+func findUserSavedJob(ctx context.Context, c *gin.Context) (models.SavedJob, bool) {
+	var job models.SavedJob
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return job, false
+	}
+	jobId, err := primitive.ObjectIDFromHex(c.Param("job_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid job_id")
+		return job, false
+	}
+
+	err = savedJobCollection.FindOne(ctx, bson.M{"_id": jobId, "user_id": userId}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "saved job not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving saved job")
+		}
+		return job, false
+	}
+	return job, true
+}
+
+// IngestJobPosting creates a saved job from the raw HTML of a job page.
+func IngestJobPosting() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var request models.IngestJobRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		job, err := utils.ParseJobPosting(request.HTML)
+		if err != nil {
+			returnError(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		// Pages are not under our control, so a salary the model would
+		// reject is dropped instead of failing the whole import.
+		if job.Salary != nil && validate.Struct(job.Salary) != nil {
+			job.Salary = nil
+		}
+		if validationErr := validate.Struct(job); validationErr != nil {
+			returnError(c, http.StatusUnprocessableEntity, validationErr.Error())
+			return
+		}
+
+		job.ID = primitive.NewObjectID()
+		job.UserID = userId
+		job.SourceURL = request.SourceURL
+		job.CreatedAt = time.Now()
+		job.UpdatedAt = time.Now()
+
+		if _, err := savedJobCollection.InsertOne(ctx, job); err != nil {
+			returnError(c, http.StatusInternalServerError, "saved job was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, job)
+	}
+}
+
+func CreateSavedJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var job models.SavedJob
+		if err := c.BindJSON(&job); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		job.Tags = utils.NormalizeTags(job.Tags)
+		if validationErr := validate.Struct(job); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		job.ID = primitive.NewObjectID()
+		job.UserID = userId
+		job.Source = models.ManualJobSource
+		job.CreatedAt = time.Now()
+		job.UpdatedAt = time.Now()
+
+		if _, err := savedJobCollection.InsertOne(ctx, job); err != nil {
+			returnError(c, http.StatusInternalServerError, "saved job was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, job)
+	}
+}
+
+func GetSavedJobs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		query, ok := listQuery(ctx, c, userId, models.SavedJobTarget)
+		if !ok {
+			return
+		}
+		filter := bson.M{"user_id": userId}
+		tagFilter(filter, query)
+
+		opts := options.Find().SetSort(bson.D{{"created_at", -1}}).SetProjection(bson.M{"description": 0})
+		cursor, err := savedJobCollection.Find(ctx, filter, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved jobs")
+			return
+		}
+		jobs := []models.SavedJob{}
+		if err := cursor.All(ctx, &jobs); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved jobs")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, jobs)
+	}
+}
+
+func GetSavedJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		job, ok := findUserSavedJob(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, job)
+	}
+}
+
+func UpdateSavedJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, ok := findUserSavedJob(ctx, c)
+		if !ok {
+			return
+		}
+
+		var job models.SavedJob
+		if err := c.BindJSON(&job); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		job.Tags = utils.NormalizeTags(job.Tags)
+		if validationErr := validate.Struct(job); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		job.ID = existing.ID
+		job.UserID = existing.UserID
+		if job.Tags == nil {
+			job.Tags = existing.Tags
+		}
+		job.Source = existing.Source
+		job.CreatedAt = existing.CreatedAt
+		job.UpdatedAt = time.Now()
+
+		if _, err := savedJobCollection.ReplaceOne(ctx, bson.M{"_id": existing.ID}, job); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating saved job")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, job)
+	}
+}
+
+func DeleteSavedJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		job, ok := findUserSavedJob(ctx, c)
+		if !ok {
+			return
+		}
+
+		if _, err := jobFitCollection.DeleteMany(ctx, bson.M{"job_id": job.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting job fits")
+			return
+		}
+		if _, err := savedJobCollection.DeleteOne(ctx, bson.M{"_id": job.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting saved job")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "saved job deleted successfully"})
+	}
+}
+
+// GetSavedJobRequirements extracts the requirements of a saved job. With
+// ?resume_id= it also checks them against that resume.
+func GetSavedJobRequirements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		job, ok := findUserSavedJob(ctx, c)
+		if !ok {
+			return
+		}
+
+		if c.Query("resume_id") == "" {
+			returnResponse(c, http.StatusOK, utils.ExtractRequirements(job.Title, job.Description, nil))
+			return
+		}
+
+		resumeId, err := primitive.ObjectIDFromHex(c.Query("resume_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid resume_id")
+			return
+		}
+		resume, ok := checkUserResume(ctx, c, job.UserID, &resumeId)
+		if !ok {
+			return
+		}
+
+		requirements := utils.ExtractRequirements(job.Title, job.Description, resume.Skills)
+		returnResponse(c, http.StatusOK, utils.CheckRequirements(requirements, *resume, time.Now()))
+	}
+}
+
+// CheckResumeRequirements checks a resume against a pasted job description.
+func CheckResumeRequirements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		resume, ok := findUserResume(ctx, c)
+		if !ok {
+			return
+		}
+
+		var request models.RequirementCheckRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		requirements := utils.ExtractRequirements(request.Title, request.Description, resume.Skills)
+		returnResponse(c, http.StatusOK, utils.CheckRequirements(requirements, resume, time.Now()))
+	}
+}
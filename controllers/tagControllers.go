@@ -0,0 +1,484 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var savedFilterCollection *mongo.Collection = database.OpenCollection(database.Client, "saved_filter")
+
+// taggedCollections are the collections whose documents carry tags.
+var taggedCollections = map[models.FilterTarget]*mongo.Collection{
+	models.ApplicationTarget: applicationCollection,
+	models.ResumeTarget:      resumeCollection,
+	models.ContactTarget:     contactCollection,
+	models.SavedJobTarget:    savedJobCollection,
+}
+
+func findUserSavedFilter(ctx context.Context, c *gin.Context) (models.SavedFilter, bool) {
+	var filter models.SavedFilter
+
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return filter, false
+	}
+	filterId, err := primitive.ObjectIDFromHex(c.Param("filter_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid filter_id")
+		return filter, false
+	}
+
+	err = savedFilterCollection.FindOne(ctx, bson.M{"_id": filterId, "user_id": userId}).Decode(&filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "saved filter not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving saved filter")
+		}
+		return filter, false
+	}
+	return filter, true
+}
+
+// listQuery returns the query parameters of a list request, with the saved
+// filter named by ?filter_id= applied. On failure the error response has
+// already been written.
+func listQuery(ctx context.Context, c *gin.Context, userId primitive.ObjectID, target models.FilterTarget) (url.Values, bool) {
+	query := c.Request.URL.Query()
+	filterId := query.Get("filter_id")
+	if filterId == "" {
+		return query, true
+	}
+	query.Del("filter_id")
+
+	id, err := primitive.ObjectIDFromHex(filterId)
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid filter_id")
+		return nil, false
+	}
+	var saved models.SavedFilter
+	err = savedFilterCollection.FindOne(ctx, bson.M{"_id": id, "user_id": userId}).Decode(&saved)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "saved filter not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving saved filter")
+		}
+		return nil, false
+	}
+	if saved.Target != target {
+		returnError(c, http.StatusBadRequest, "saved filter "+saved.Name+" is for "+string(saved.Target))
+		return nil, false
+	}
+
+	return utils.ApplySavedFilter(saved, query), true
+}
+
+// tagFilter narrows a list filter to documents carrying every tag of
+// ?tags=.
+func tagFilter(filter bson.M, query url.Values) {
+	if tags := utils.ParseTagList(query.Get("tags")); len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": tags}
+	}
+}
+
+// checkSavedFilter validates a saved filter by building the list filter it
+// stands for, so bad dates and ids are caught when it is saved rather than
+// when it is used.
+func checkSavedFilter(c *gin.Context, filter *models.SavedFilter) bool {
+	filter.Tags = utils.NormalizeTags(filter.Tags)
+	if validationErr := validate.Struct(filter); validationErr != nil {
+		returnError(c, http.StatusBadRequest, validationErr.Error())
+		return false
+	}
+	if err := utils.CheckSavedFilter(*filter); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	query := url.Values{}
+	for param, value := range filter.Query {
+		query.Set(param, value)
+	}
+	var err error
+	switch filter.Target {
+	case models.ApplicationTarget:
+		_, err = applicationFilter(query, filter.UserID)
+	case models.ContactTarget:
+		_, err = contactFilter(query, filter.UserID)
+	}
+	if err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// setTags replaces the tags of the document named by the param route
+// parameter in the target's collection.
+func setTags(ctx context.Context, c *gin.Context, target models.FilterTarget, param string, name string) {
+	userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(c.Param(param))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid "+param)
+		return
+	}
+
+	var request models.TagsRequest
+	if err := c.BindJSON(&request); err != nil {
+		returnError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	request.Tags = utils.NormalizeTags(request.Tags)
+	if request.Tags == nil {
+		request.Tags = []string{}
+	}
+	if validationErr := validate.Struct(request); validationErr != nil {
+		returnError(c, http.StatusBadRequest, validationErr.Error())
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"tags": request.Tags, "updated_at": time.Now()}}
+	if len(request.Tags) == 0 {
+		update = bson.M{"$unset": bson.M{"tags": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+	result, err := taggedCollections[target].UpdateOne(ctx, bson.M{"_id": id, "user_id": userId}, update)
+	if err != nil {
+		returnError(c, http.StatusInternalServerError, "error occurred while updating tags")
+		return
+	}
+	if result.MatchedCount == 0 {
+		returnError(c, http.StatusNotFound, name+" not found")
+		return
+	}
+
+	returnResponse(c, http.StatusOK, request)
+}
+
+func SetResumeTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		setTags(ctx, c, models.ResumeTarget, "resume_id", "resume")
+	}
+}
+
+func SetApplicationTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		setTags(ctx, c, models.ApplicationTarget, "application_id", "application")
+	}
+}
+
+func SetContactTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		setTags(ctx, c, models.ContactTarget, "contact_id", "contact")
+	}
+}
+
+func SetSavedJobTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		setTags(ctx, c, models.SavedJobTarget, "job_id", "saved job")
+	}
+}
+
+// GetTags lists every tag the user has used with how often it is used in
+// each list, most used first.
+func GetTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		matchStage := bson.D{{"$match", bson.D{{"user_id", userId}, {"tags.0", bson.D{{"$exists", true}}}}}}
+		unwindStage := bson.D{{"$unwind", "$tags"}}
+		groupStage := bson.D{{"$group", bson.D{{"_id", "$tags"}, {"count", bson.D{{"$sum", 1}}}}}}
+
+		usage := map[string]*models.TagUsage{}
+		for _, target := range models.FilterTargets {
+			cursor, err := taggedCollections[target].Aggregate(ctx, mongo.Pipeline{matchStage, unwindStage, groupStage})
+			if err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while listing tags")
+				return
+			}
+			var counts []struct {
+				Name  string `bson:"_id"`
+				Count int    `bson:"count"`
+			}
+			if err := cursor.All(ctx, &counts); err != nil {
+				returnError(c, http.StatusInternalServerError, "error occurred while listing tags")
+				return
+			}
+			for _, count := range counts {
+				tag, ok := usage[count.Name]
+				if !ok {
+					tag = &models.TagUsage{Name: count.Name, Counts: map[models.FilterTarget]int{}}
+					usage[count.Name] = tag
+				}
+				tag.Counts[target] = count.Count
+				tag.Total += count.Count
+			}
+		}
+
+		tags := []models.TagUsage{}
+		for _, tag := range usage {
+			tags = append(tags, *tag)
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].Total != tags[j].Total {
+				return tags[i].Total > tags[j].Total
+			}
+			return tags[i].Name < tags[j].Name
+		})
+
+		returnResponse(c, http.StatusOK, tags)
+	}
+}
+
+// renameTag replaces a tag in every tagged collection and in saved
+// filters. Documents that already carry the new tag keep a single copy.
+func renameTag(ctx context.Context, userId primitive.ObjectID, from string, to string) error {
+	collections := []*mongo.Collection{savedFilterCollection}
+	for _, target := range models.FilterTargets {
+		collections = append(collections, taggedCollections[target])
+	}
+
+	for _, collection := range collections {
+		filter := bson.M{"user_id": userId, "tags": from}
+		if to != "" {
+			if _, err := collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": to}}); err != nil {
+				return err
+			}
+		}
+		if _, err := collection.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": from}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RenameTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var request models.RenameTagRequest
+		if err := c.BindJSON(&request); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		request.Name = utils.NormalizeTag(request.Name)
+		if validationErr := validate.Struct(request); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		from := utils.NormalizeTag(c.Param("tag"))
+		if from == request.Name {
+			returnResponse(c, http.StatusOK, request)
+			return
+		}
+		if err := renameTag(ctx, userId, from, request.Name); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while renaming tag")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, request)
+	}
+}
+
+// DeleteTag removes a tag from everything that carries it. Saved filters
+// that relied on it keep their other conditions.
+func DeleteTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		if err := renameTag(ctx, userId, utils.NormalizeTag(c.Param("tag")), ""); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting tag")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "tag deleted successfully"})
+	}
+}
+
+func CreateSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		var filter models.SavedFilter
+		if err := c.BindJSON(&filter); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.UserID = userId
+		if !checkSavedFilter(c, &filter) {
+			return
+		}
+
+		filter.ID = primitive.NewObjectID()
+		filter.CreatedAt = time.Now()
+		filter.UpdatedAt = time.Now()
+
+		if _, err := savedFilterCollection.InsertOne(ctx, filter); err != nil {
+			returnError(c, http.StatusInternalServerError, "saved filter was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, filter)
+	}
+}
+
+// GetSavedFilters lists the user's saved filters by name, optionally only
+// those for ?target=.
+func GetSavedFilters() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			returnError(c, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+
+		filter := bson.M{"user_id": userId}
+		if target := c.Query("target"); target != "" {
+			if _, ok := models.FilterParams[models.FilterTarget(target)]; !ok {
+				returnError(c, http.StatusBadRequest, "target must be one of applications, resumes, contacts, saved_jobs")
+				return
+			}
+			filter["target"] = target
+		}
+
+		cursor, err := savedFilterCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{"name", 1}}))
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved filters")
+			return
+		}
+		filters := []models.SavedFilter{}
+		if err := cursor.All(ctx, &filters); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing saved filters")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, filters)
+	}
+}
+
+func GetSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter, ok := findUserSavedFilter(ctx, c)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, filter)
+	}
+}
+
+func UpdateSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, ok := findUserSavedFilter(ctx, c)
+		if !ok {
+			return
+		}
+
+		var filter models.SavedFilter
+		if err := c.BindJSON(&filter); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.UserID = existing.UserID
+		if !checkSavedFilter(c, &filter) {
+			return
+		}
+
+		filter.ID = existing.ID
+		filter.CreatedAt = existing.CreatedAt
+		filter.UpdatedAt = time.Now()
+
+		if _, err := savedFilterCollection.ReplaceOne(ctx, bson.M{"_id": existing.ID}, filter); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating saved filter")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, filter)
+	}
+}
+
+func DeleteSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		filter, ok := findUserSavedFilter(ctx, c)
+		if !ok {
+			return
+		}
+
+		if _, err := savedFilterCollection.DeleteOne(ctx, bson.M{"_id": filter.ID}); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while deleting saved filter")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{"msg": "saved filter deleted successfully"})
+	}
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var noteCollection *mongo.Collection = database.OpenCollection(database.Client, "application_note")
+
+func findApplicationNote(ctx context.Context, c *gin.Context, application models.JobApplication) (models.ApplicationNote, bool) {
+	var note models.ApplicationNote
+
+	noteId, err := primitive.ObjectIDFromHex(c.Param("note_id"))
+	if err != nil {
+		returnError(c, http.StatusBadRequest, "Invalid note_id")
+		return note, false
+	}
+
+	err = noteCollection.FindOne(ctx, bson.M{"_id": noteId, "application_id": application.ID}).Decode(&note)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			returnError(c, http.StatusNotFound, "note not found")
+		} else {
+			returnError(c, http.StatusInternalServerError, "error occurred while retrieving note")
+		}
+		return note, false
+	}
+	return note, true
+}
+
+// findAll decodes every document matching filter into results.
+func findAll(ctx context.Context, collection *mongo.Collection, filter interface{}, results interface{}) error {
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+func CreateApplicationNote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		var note models.ApplicationNote
+		if err := c.BindJSON(&note); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(note); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+
+		note.ID = primitive.NewObjectID()
+		note.UserID = application.UserID
+		note.ApplicationID = application.ID
+		note.Revisions = []models.NoteRevision{}
+		note.CreatedAt = time.Now()
+		note.UpdatedAt = time.Now()
+
+		if _, err := noteCollection.InsertOne(ctx, note); err != nil {
+			returnError(c, http.StatusInternalServerError, "note was not created")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, note)
+	}
+}
+
+func GetApplicationNotes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{"created_at", -1}})
+		cursor, err := noteCollection.Find(ctx, bson.M{"application_id": application.ID}, opts)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing notes")
+			return
+		}
+		notes := []models.ApplicationNote{}
+		if err := cursor.All(ctx, &notes); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while listing notes")
+			return
+		}
+
+		returnResponse(c, http.StatusOK, notes)
+	}
+}
+
+func GetApplicationNote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		note, ok := findApplicationNote(ctx, c, application)
+		if !ok {
+			return
+		}
+
+		returnResponse(c, http.StatusOK, note)
+	}
+}
+
+// UpdateApplicationNote replaces the text of a note and keeps the old text
+// as a revision.
+func UpdateApplicationNote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		note, ok := findApplicationNote(ctx, c, application)
+		if !ok {
+			return
+		}
+
+		var edit models.ApplicationNote
+		if err := c.BindJSON(&edit); err != nil {
+			returnError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if validationErr := validate.Struct(edit); validationErr != nil {
+			returnError(c, http.StatusBadRequest, validationErr.Error())
+			return
+		}
+		if edit.Body == note.Body {
+			returnResponse(c, http.StatusOK, note)
+			return
+		}
+
+		now := time.Now()
+		revision := models.NoteRevision{Body: note.Body, ReplacedAt: now}
+
+		// Matching on the old text makes a concurrent edit fail instead of
+		// dropping the other edit from the history.
+		result, err := noteCollection.UpdateOne(ctx,
+			bson.M{"_id": note.ID, "body": note.Body},
+			bson.M{
+				"$set":  bson.M{"body": edit.Body, "updated_at": now},
+				"$push": bson.M{"revisions": revision},
+			},
+		)
+		if err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while updating note")
+			return
+		}
+		if result.MatchedCount == 0 {
+			returnError(c, http.StatusConflict, "note was changed by another request")
+			return
+		}
+
+		note.Body = edit.Body
+		note.Revisions = append(note.Revisions, revision)
+		note.UpdatedAt = now
+		returnResponse(c, http.StatusOK, note)
+	}
+}
+
+// GetApplicationTimeline returns the activity feed of an application,
+// newest first. ?kind= takes a comma separated list of event kinds.
+func GetApplicationTimeline() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		application, ok := findUserApplication(ctx, c)
+		if !ok {
+			return
+		}
+
+		recordPerPage := 20
+		page := 1
+		if rpp, err := strconv.Atoi(c.Query("recordPerPage")); err == nil && rpp > 0 {
+			recordPerPage = rpp
+		}
+		if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+			page = p
+		}
+
+		sources := utils.TimelineSources{Application: application}
+		byApplication := bson.M{"application_id": application.ID}
+
+		documentFilter := byApplication
+		if application.DocumentID != nil {
+			documentFilter = bson.M{"$or": bson.A{byApplication, bson.M{"_id": *application.DocumentID}}}
+		}
+		documentFilter = bson.M{"$and": bson.A{documentFilter, bson.M{"user_id": application.UserID}}}
+
+		if err := findAll(ctx, noteCollection, byApplication, &sources.Notes); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading notes")
+			return
+		}
+		if err := findAll(ctx, documentCollection, documentFilter, &sources.Documents); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading documents")
+			return
+		}
+		if err := findAll(ctx, reminderCollection, byApplication, &sources.Reminders); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading reminders")
+			return
+		}
+		if err := findAll(ctx, interactionCollection, byApplication, &sources.Interactions); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading interactions")
+			return
+		}
+		contactIds := []primitive.ObjectID{}
+		for _, interaction := range sources.Interactions {
+			contactIds = append(contactIds, interaction.ContactID)
+		}
+		if err := findAll(ctx, contactCollection, bson.M{"_id": bson.M{"$in": contactIds}}, &sources.Contacts); err != nil {
+			returnError(c, http.StatusInternalServerError, "error occurred while reading contacts")
+			return
+		}
+
+		events := utils.BuildTimeline(sources)
+		if kinds := c.Query("kind"); kinds != "" {
+			wanted := map[models.TimelineEventKind]bool{}
+			for _, kind := range strings.Split(kinds, ",") {
+				wanted[models.TimelineEventKind(kind)] = true
+			}
+			filtered := []models.TimelineEvent{}
+			for _, event := range events {
+				if wanted[event.Kind] {
+					filtered = append(filtered, event)
+				}
+			}
+			events = filtered
+		}
+
+		totalCount := len(events)
+		start := (page - 1) * recordPerPage
+		if start > totalCount {
+			start = totalCount
+		}
+		end := start + recordPerPage
+		if end > totalCount {
+			end = totalCount
+		}
+
+		returnResponse(c, http.StatusOK, gin.H{
+			"total_count":   totalCount,
+			"events":        events[start:end],
+			"page":          page,
+			"recordPerPage": recordPerPage,
+		})
+	}
+}
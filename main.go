@@ -1,11 +1,17 @@
 package main
 
 import (
-	"crafter/database"
+	"context"
 	"fmt"
+	"log"
 	"os"
 
+	"crafter/controllers"
+	"crafter/database"
+	"crafter/middleware"
+	"crafter/reminders"
 	"crafter/routes"
+	"crafter/storage"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,6 +29,40 @@ func main() {
 	}
 	router := gin.New()
 	router.Use(gin.Logger())
+	// Authentication runs on every route; it lets the public ones through.
+	router.Use(middleware.Authenticate())
 	routes.UserRoutes(router)
+	routes.ResumeRoutes(router)
+	routes.DocumentRoutes(router)
+	routes.CoverLetterRoutes(router)
+	routes.JobApplicationRoutes(router)
+	routes.ReminderRoutes(router)
+	routes.InterviewRoutes(router)
+	routes.ContactRoutes(router)
+	routes.SavedJobRoutes(router)
+	routes.AnalyticsRoutes(router)
+	routes.EmailRoutes(router)
+	routes.OfferRoutes(router)
+	routes.TagRoutes(router)
+
+	blobs, err := storage.BlobInstance()
+	if err != nil {
+		log.Fatalf("opening blob storage failed: %v", err)
+	}
+	storage.Blobs = blobs
+
+	if err := controllers.MigrateResumeURLs(context.Background()); err != nil {
+		log.Fatalf("migrating resume_urls to documents failed: %v", err)
+	}
+	if err := controllers.MigrateEntryIDs(context.Background()); err != nil {
+		log.Fatalf("assigning resume entry ids failed: %v", err)
+	}
+	if err := controllers.EnsureResumeSearchIndex(context.Background()); err != nil {
+		log.Fatalf("creating the resume search index failed: %v", err)
+	}
+
+	// The reminder worker runs next to the server for as long as it is up.
+	go reminders.NewWorker(reminders.NotifierInstance()).Run(context.Background())
+
 	router.Run(":" + port)
 }
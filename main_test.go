@@ -2,14 +2,30 @@ package main
 
 import (
 	"crafter/controllers"
+	"crafter/middleware"
 	"crafter/models"
+	"crafter/reminders"
+	"crafter/storage"
+	"crafter/utils"
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -33,16 +49,16 @@ func TestSignUp_Success(t *testing.T) {
 	router.POST("/users/signup", controllers.SignUp())
 
 	user := models.User{
-		First_name:    stringPointer("John"),
-		Last_name:     stringPointer("Doe"),
-		Date_of_birth: stringPointer("02-09-2002"),
-		Password:      stringPointer("Password123"),
-		Email:         stringPointer("john.doe@example.com"),
-		UserType:      models.Professional,
-		Experience:    models.Fresher,
-		Created_at:    time.Now(),
-		Updated_at:    time.Now(),
-		ID:            primitive.NewObjectID(),
+		FirstName:   "John",
+		LastName:    "Doe",
+		DateOfBirth: time.Date(2002, time.September, 2, 0, 0, 0, 0, time.UTC),
+		Password:    "Password123",
+		Email:       "john.doe@example.com",
+		UserType:    models.Professional,
+		Experience:  models.Fresher,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		ID:          primitive.NewObjectID(),
 	}
 
 	jsonValue, _ := json.Marshal(user)
@@ -67,12 +83,12 @@ func TestSignUp_MissingFields(t *testing.T) {
 	router.POST("/users/signup", controllers.SignUp())
 
 	user := models.User{
-		Last_name:     stringPointer("Doe"),
-		Date_of_birth: stringPointer("02-09-2002"),
-		Password:      stringPointer("Password123"),
-		Email:         stringPointer("john.doe@example.com"),
-		UserType:      models.Professional,
-		Experience:    models.Fresher,
+		LastName:    "Doe",
+		DateOfBirth: time.Date(2002, time.September, 2, 0, 0, 0, 0, time.UTC),
+		Password:    "Password123",
+		Email:       "john.doe@example.com",
+		UserType:    models.Professional,
+		Experience:  models.Fresher,
 	}
 
 	jsonValue, _ := json.Marshal(user)
@@ -97,13 +113,13 @@ func TestSignUp_InvalidEnum(t *testing.T) {
 	router.POST("/users/signup", controllers.SignUp())
 
 	user := models.User{
-		First_name:    stringPointer("John"),
-		Last_name:     stringPointer("Doe"),
-		Date_of_birth: stringPointer("02-09-2002"),
-		Password:      stringPointer("Password123"),
-		Email:         stringPointer("john.doe@example.com"),
-		UserType:      "InvalidType",       // Invalid UserType
-		Experience:    "InvalidExperience", // Invalid ExperienceLevel
+		FirstName:   "John",
+		LastName:    "Doe",
+		DateOfBirth: time.Date(2002, time.September, 2, 0, 0, 0, 0, time.UTC),
+		Password:    "Password123",
+		Email:       "john.doe@example.com",
+		UserType:    "InvalidType",       // Invalid UserType
+		Experience:  "InvalidExperience", // Invalid ExperienceLevel
 	}
 
 	jsonValue, _ := json.Marshal(user)
@@ -127,7 +143,7 @@ func TestSignUp_DuplicateEmail(t *testing.T) {
 
 	// Assume there's already a user in the system
 	existingUser := models.User{
-		Email: stringPointer("john.doe@example.com"),
+		Email: "john.doe@example.com",
 	}
 	mockUserCollection = append(mockUserCollection, existingUser)
 
@@ -135,13 +151,13 @@ func TestSignUp_DuplicateEmail(t *testing.T) {
 	router.POST("/users/signup", controllers.SignUp())
 
 	user := models.User{
-		First_name:    stringPointer("John"),
-		Last_name:     stringPointer("Doe"),
-		Date_of_birth: stringPointer("02-09-2002"),
-		Password:      stringPointer("Password123"),
-		Email:         stringPointer("john.doe@example.com"), // Duplicate email
-		UserType:      models.Professional,
-		Experience:    models.Fresher,
+		FirstName:   "John",
+		LastName:    "Doe",
+		DateOfBirth: time.Date(2002, time.September, 2, 0, 0, 0, 0, time.UTC),
+		Password:    "Password123",
+		Email:       "john.doe@example.com", // Duplicate email
+		UserType:    models.Professional,
+		Experience:  models.Fresher,
 	}
 
 	jsonValue, err := json.Marshal(user)
@@ -160,3 +176,1675 @@ func TestSignUp_DuplicateEmail(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "this email already exists")
 }
+
+// TestRedactResume_PublicWeb tests that the public web profile holds back contact details
+//
+// The test redacts a resume with the public web profile and asserts that the
+// phone number, email and GPA are cleared, the location is cut down to the
+// country and the original resume is left untouched.
+func TestRedactResume_PublicWeb(t *testing.T) {
+	resume := models.Resume{
+		Name:        "John Doe",
+		Email:       "john.doe@example.com",
+		PhoneNumber: "+1 555 0100",
+		Location:    "Indiranagar, Bengaluru, Karnataka, India",
+		Education: []models.Education{
+			{Name: "State University", GPA: 3.8},
+		},
+	}
+
+	redacted, err := utils.RedactResume(resume, models.PublicWebProfile)
+
+	assert.NoError(t, err)
+	assert.Empty(t, redacted.Email)
+	assert.Empty(t, redacted.PhoneNumber)
+	assert.Equal(t, "India", redacted.Location)
+	assert.Zero(t, redacted.Education[0].GPA)
+	assert.Equal(t, 3.8, resume.Education[0].GPA)
+
+	_, err = utils.RedactResume(resume, "unknown")
+	assert.Error(t, err)
+}
+
+// TestRedactResume_FreeText tests that contact details are scrubbed from resume free text
+//
+// The test redacts a resume whose summary, bullet points and custom section
+// repeat the email and phone number, and asserts that the public web profile
+// replaces them, the full profile keeps them and the original is untouched.
+func TestRedactResume_FreeText(t *testing.T) {
+	summary := "Reach me at john.doe@example.com or +1 555 010 0199."
+	resume := models.Resume{
+		Name:        "John Doe",
+		Email:       "john.doe@example.com",
+		PhoneNumber: "+1 555 010 0199",
+		Summary:     &summary,
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme", BulletPoints: []string{"Grew revenue 20% in 2019-2021", "On call at +1 555 010 0199"}},
+		},
+		CustomSections: []models.CustomSection{
+			{Title: "Contact", Type: models.KeyValueSection, Entries: []models.CustomEntry{{Key: "Mail", Value: "john.doe@example.com"}}},
+		},
+	}
+
+	redacted, err := utils.RedactResume(resume, models.PublicWebProfile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Reach me at [redacted] or [redacted].", *redacted.Summary)
+	assert.Equal(t, []string{"Grew revenue 20% in 2019-2021", "On call at [redacted]"}, redacted.WorkExperience[0].BulletPoints)
+	assert.Equal(t, "[redacted]", redacted.CustomSections[0].Entries[0].Value)
+	assert.Equal(t, summary, *resume.Summary)
+	assert.Equal(t, "On call at +1 555 010 0199", resume.WorkExperience[0].BulletPoints[1])
+	assert.Equal(t, "john.doe@example.com", resume.CustomSections[0].Entries[0].Value)
+
+	full, err := utils.RedactResume(resume, models.FullProfile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, summary, *full.Summary)
+}
+
+// TestDeleteResumeShare_InvalidID tests that revoking a share checks the share id
+//
+// The test sends a revoke request with a malformed share id and asserts that
+// it is rejected before the share collection is touched.
+func TestDeleteResumeShare_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.DELETE("/users/:user_id/resumes/:resume_id/shares/:share_id", controllers.DeleteResumeShare())
+
+	userId := primitive.NewObjectID().Hex()
+	resumeId := primitive.NewObjectID().Hex()
+	req, _ := http.NewRequest("DELETE", "/users/"+userId+"/resumes/"+resumeId+"/shares/not-an-id", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid share_id")
+}
+
+// TestRenderResume_JSONProjection tests that JSON exports only carry rendered sections
+//
+// The test renders a tagged resume with a layout and asserts that the JSON
+// export has no ids, tags or layout, keeps the layout's section order and
+// leaves out hidden and empty sections.
+func TestRenderResume_JSONProjection(t *testing.T) {
+	summary := "Backend engineer"
+	resume := models.Resume{
+		ID:             primitive.NewObjectID(),
+		UserID:         primitive.NewObjectID(),
+		LocaleGroupID:  primitive.NewObjectID(),
+		Name:           "John Doe",
+		Summary:        &summary,
+		Skills:         []string{"Go", "MongoDB"},
+		Languages:      []string{"English"},
+		WorkExperience: []models.WorkExperience{{CompanyName: "Acme", RoleTitle: "Engineer"}},
+		Tags:           []string{"backend"},
+		Layout:         &models.ResumeLayout{SectionOrder: []string{"skills"}, HiddenSections: []string{"languages"}},
+	}
+
+	body, contentType, err := utils.RenderResume(resume, utils.JSONFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json; charset=utf-8", contentType)
+	for _, field := range []string{"user_id", "tags", "layout", "locale_group_id", resume.ID.Hex(), resume.UserID.Hex()} {
+		assert.NotContains(t, string(body), field)
+	}
+
+	var export struct {
+		Name     string `json:"name"`
+		Sections []struct {
+			Key     string          `json:"key"`
+			Heading string          `json:"heading"`
+			Content json.RawMessage `json:"content"`
+		} `json:"sections"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &export))
+	assert.Equal(t, "John Doe", export.Name)
+	keys := []string{}
+	for _, section := range export.Sections {
+		keys = append(keys, section.Key)
+	}
+	assert.Equal(t, []string{"skills", "summary", "work_experience"}, keys)
+	assert.Equal(t, "Skills", export.Sections[0].Heading)
+	assert.JSONEq(t, `["Go","MongoDB"]`, string(export.Sections[0].Content))
+}
+
+// TestValidateCustomSection tests that custom section entries match their type
+//
+// The test validates one well formed section of each type and then checks
+// that entries missing the fields their type needs, dated entries that end
+// before they start and unknown section types are all rejected.
+func TestValidateCustomSection(t *testing.T) {
+	start := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := []models.CustomSection{
+		{Title: "Publications", Type: models.BulletsSection, Entries: []models.CustomEntry{{Text: "Scaling Go services"}}},
+		{Title: "Volunteering", Type: models.DatedSection, Entries: []models.CustomEntry{{Title: "Mentor", StartDate: start}}},
+		{Title: "Links", Type: models.KeyValueSection, Entries: []models.CustomEntry{{Key: "Blog", Value: "example.com"}}},
+	}
+	for _, section := range valid {
+		assert.NoError(t, utils.ValidateCustomSection(section), section.Title)
+	}
+
+	invalid := []models.CustomSection{
+		{Type: models.BulletsSection, Entries: []models.CustomEntry{{Text: "  "}}},
+		{Type: models.DatedSection, Entries: []models.CustomEntry{{StartDate: start}}},
+		{Type: models.DatedSection, Entries: []models.CustomEntry{{Title: "Mentor"}}},
+		{Type: models.DatedSection, Entries: []models.CustomEntry{{Title: "Mentor", StartDate: start, EndDate: start.AddDate(0, -1, 0)}}},
+		{Type: models.KeyValueSection, Entries: []models.CustomEntry{{Key: "Blog"}}},
+		{Type: "table", Entries: []models.CustomEntry{{Text: "cell"}}},
+	}
+	for _, section := range invalid {
+		assert.Error(t, utils.ValidateCustomSection(section), string(section.Type))
+	}
+}
+
+// TestCustomSectionEndpoints_InvalidBody tests that bad custom section requests are rejected
+//
+// The test sends a section with an unknown type to the create and update
+// endpoints and an empty order to the reorder endpoint, and asserts that
+// each one is refused with a 400 before the resume is looked up.
+func TestCustomSectionEndpoints_InvalidBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/resumes/:resume_id/sections", controllers.CreateCustomSection())
+	router.PUT("/users/:user_id/resumes/:resume_id/sections/order", controllers.ReorderCustomSections())
+	router.PUT("/users/:user_id/resumes/:resume_id/sections/:section_id", controllers.UpdateCustomSection())
+
+	path := "/users/" + primitive.NewObjectID().Hex() + "/resumes/" + primitive.NewObjectID().Hex() + "/sections"
+	section, _ := json.Marshal(models.CustomSection{
+		Title:   "Publications",
+		Type:    "table",
+		Entries: []models.CustomEntry{{Text: "Scaling Go services"}},
+	})
+
+	requests := []struct {
+		method string
+		path   string
+		body   []byte
+	}{
+		{"POST", path, section},
+		{"PUT", path + "/" + primitive.NewObjectID().Hex(), section},
+		{"PUT", path + "/order", []byte(`{}`)},
+	}
+	for _, request := range requests {
+		req, _ := http.NewRequest(request.method, request.path, bytes.NewBuffer(request.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, request.path)
+		assert.Contains(t, w.Body.String(), "error")
+	}
+}
+
+// TestReorderCustomSections tests moving custom sections around
+//
+// The test checks that a new section is placed after the existing ones,
+// that reordering sets positions from the given ids without touching the
+// original sections, and that duplicate, missing and unknown ids are
+// rejected.
+func TestReorderCustomSections(t *testing.T) {
+	a := models.CustomSection{ID: primitive.NewObjectID(), Title: "A", Position: 0}
+	b := models.CustomSection{ID: primitive.NewObjectID(), Title: "B", Position: 3}
+	c := models.CustomSection{ID: primitive.NewObjectID(), Title: "C", Position: 1}
+	sections := []models.CustomSection{a, b, c}
+
+	assert.Equal(t, 0, utils.NextCustomSectionPosition(nil))
+	assert.Equal(t, 4, utils.NextCustomSectionPosition(sections))
+
+	reordered, err := utils.ReorderCustomSections(sections, []primitive.ObjectID{c.ID, a.ID, b.ID})
+	assert.NoError(t, err)
+	titles := []string{}
+	for _, section := range utils.SortedCustomSections(reordered) {
+		titles = append(titles, section.Title)
+	}
+	assert.Equal(t, []string{"C", "A", "B"}, titles)
+	assert.Equal(t, 3, sections[1].Position)
+
+	_, err = utils.ReorderCustomSections(sections, []primitive.ObjectID{a.ID, a.ID, b.ID})
+	assert.Error(t, err)
+	_, err = utils.ReorderCustomSections(sections, []primitive.ObjectID{a.ID, b.ID})
+	assert.Error(t, err)
+	_, err = utils.ReorderCustomSections(sections, []primitive.ObjectID{a.ID, b.ID, primitive.NewObjectID()})
+	assert.Error(t, err)
+}
+
+// TestRenderResume_CustomSections tests that custom sections are rendered in order
+//
+// The test renders a resume with a bullets, a dated and a key_value section
+// to Markdown and asserts that each entry is written in its type's format
+// and that the sections appear in position order after the built-in ones.
+func TestRenderResume_CustomSections(t *testing.T) {
+	resume := models.Resume{
+		Name:   "John Doe",
+		Skills: []string{"Go"},
+		CustomSections: []models.CustomSection{
+			{ID: primitive.NewObjectID(), Title: "Links", Type: models.KeyValueSection, Position: 2,
+				Entries: []models.CustomEntry{{Key: "Blog", Value: "example.com"}}},
+			{ID: primitive.NewObjectID(), Title: "Publications", Type: models.BulletsSection, Position: 0,
+				Entries: []models.CustomEntry{{Text: "Scaling Go services"}}},
+			{ID: primitive.NewObjectID(), Title: "Volunteering", Type: models.DatedSection, Position: 1,
+				Entries: []models.CustomEntry{{
+					Title:       "Mentor",
+					Subtitle:    "Code Club",
+					StartDate:   time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+					Description: "Taught weekly classes",
+				}}},
+		},
+	}
+
+	body, _, err := utils.RenderResume(resume, utils.MarkdownFormat)
+	assert.NoError(t, err)
+	markdown := string(body)
+
+	assert.Contains(t, markdown, "- Scaling Go services\n")
+	assert.Contains(t, markdown, "### Mentor\nCode Club | ")
+	assert.Contains(t, markdown, "Taught weekly classes\n")
+	assert.Contains(t, markdown, "- **Blog:** example.com\n")
+
+	skills := strings.Index(markdown, "Go")
+	publications := strings.Index(markdown, "Publications")
+	volunteering := strings.Index(markdown, "Volunteering")
+	links := strings.Index(markdown, "Links")
+	assert.True(t, skills < publications && publications < volunteering && volunteering < links, markdown)
+}
+
+// TestSearchResumes tests that search hits point back to the matching text
+//
+// The test searches two resumes and asserts that only text holding every
+// term matches, that hits carry the resume, section and entry they came
+// from, that the section filter applies and that the highlight escapes the
+// user's text before marking the matches.
+func TestSearchResumes(t *testing.T) {
+	summary := "Engineer who <script>alert(1)</script> reduced p99 latency"
+	talks := models.CustomSection{
+		ID:      primitive.NewObjectID(),
+		Title:   "Talks",
+		Type:    models.BulletsSection,
+		Entries: []models.CustomEntry{{ID: primitive.NewObjectID(), Text: "Cutting p99 latency at GopherCon"}},
+	}
+	acme := models.WorkExperience{
+		ID:           primitive.NewObjectID(),
+		CompanyName:  "Acme",
+		RoleTitle:    "Engineer",
+		BulletPoints: []string{"Wrote internal tools", "Reduced p99 latency of the payments API by 40%"},
+	}
+	tailored := models.Resume{ID: primitive.NewObjectID(), Locale: "en", Summary: &summary, WorkExperience: []models.WorkExperience{acme}}
+	general := models.Resume{ID: primitive.NewObjectID(), Skills: []string{"Latency tuning"}, CustomSections: []models.CustomSection{talks}}
+
+	hits := utils.SearchResumes([]models.Resume{tailored, general}, "p99 LATENCY", "")
+	assert.Len(t, hits, 3)
+
+	sections := map[string]models.SearchHit{}
+	for _, hit := range hits {
+		sections[hit.Section] = hit
+	}
+
+	work := sections["work_experience"]
+	assert.Equal(t, tailored.ID, work.ResumeID)
+	assert.Equal(t, "en", work.Locale)
+	assert.Equal(t, 0, work.EntryIndex)
+	assert.Equal(t, acme.ID.Hex(), work.EntryID)
+	assert.Equal(t, 1, work.BulletIndex)
+	assert.Equal(t, "Reduced <mark>p99</mark> <mark>latency</mark> of the payments API by 40%", work.Highlight)
+
+	custom := sections["custom_sections"]
+	assert.Equal(t, general.ID, custom.ResumeID)
+	assert.Equal(t, talks.ID.Hex(), custom.SectionID)
+	assert.Equal(t, talks.Entries[0].ID.Hex(), custom.EntryID)
+
+	escaped := sections["summary"]
+	assert.Equal(t, summary, escaped.Text)
+	assert.NotContains(t, escaped.Highlight, "<script>")
+	assert.Contains(t, escaped.Highlight, "&lt;script&gt;")
+	assert.Contains(t, escaped.Highlight, "<mark>p99</mark>")
+
+	hits = utils.SearchResumes([]models.Resume{tailored, general}, "p99 latency", "custom_sections")
+	assert.Len(t, hits, 1)
+	assert.Empty(t, utils.SearchResumes([]models.Resume{tailored}, "lat", ""))
+
+	assert.Equal(t, `"p99" "latency"`, utils.SearchQuery("p99, Latency!"))
+	assert.Empty(t, utils.SearchQuery("--"))
+}
+
+// TestNeutralizePronouns tests that gendered pronouns in bullet points are replaced
+//
+// The test runs a bullet containing several gendered pronouns through the
+// anonymizer and asserts that each one is replaced with its neutral form
+// while keeping the original capitalization, that "her" is told apart as
+// possessive or object and that an ambiguous "her" is left as written.
+func TestNeutralizePronouns(t *testing.T) {
+	bullet := "She led her team of 5 and mentored him; He credits himself"
+
+	assert.Equal(t, "They led their team of 5 and mentored them; They credits themselves", utils.NeutralizePronouns(bullet))
+
+	cases := map[string]string{
+		"Gave her the award":        "Gave them the award",
+		"Mentored her and two more": "Mentored them and two more",
+		"Worked with her manager":   "Worked with their manager",
+		"Her work shipped":          "Their work shipped",
+		"Helped her finish":         "Helped her finish",
+	}
+	for bullet, expected := range cases {
+		assert.Equal(t, expected, utils.NeutralizePronouns(bullet), bullet)
+	}
+}
+
+// TestAnonymizeResume tests that blind-hiring anonymization scrubs every identifying field
+//
+// The test anonymizes a resume that has pronouns in every free-text field
+// and links and locations in every section, and asserts that each of them
+// is replaced, that the identifiers are cleared, that every replacement is
+// reported and that an ambiguous pronoun is flagged for review.
+func TestAnonymizeResume(t *testing.T) {
+	summary := "She builds APIs"
+	description := "Her side project"
+	projectURL := "https://github.com/jane/side"
+	linkedIn := "https://linkedin.com/in/jane"
+	resume := models.Resume{
+		ID:            primitive.NewObjectID(),
+		UserID:        primitive.NewObjectID(),
+		LocaleGroupID: primitive.NewObjectID(),
+		Name:          "Jane Doe",
+		Email:         "jane@example.com",
+		PhoneNumber:   "+1 555 0100",
+		Location:      "Austin, Texas, USA",
+		LinkedInLink:  &linkedIn,
+		Summary:       &summary,
+		Tags:          []string{"backend"},
+		Education: []models.Education{
+			{Name: "State University", Location: "Austin", EndDate: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme", Location: "Austin, Texas", BulletPoints: []string{"He shipped his service"}},
+		},
+		Projects: []models.Project{
+			{Name: "Side", Description: &description, ProjectUrl: &projectURL, BulletPoints: []string{"Built it herself"}},
+		},
+		Certifications: []models.Certification{
+			{Title: "CKA", Description: "Earned by him in 2021", CertificateLink: "https://certs.example.com/jane"},
+		},
+		HonorsAwards:     []models.HonorAward{{Title: "Her award", Description: "Given to him"}},
+		Extracurriculars: []models.Extracurricular{{ActivityName: "Chess", Description: "She captained the team and helped her finish"}},
+		CustomSections: []models.CustomSection{{
+			ID:    primitive.NewObjectID(),
+			Title: "Talks",
+			Type:  models.DatedSection,
+			Entries: []models.CustomEntry{
+				{Title: "His talk", Subtitle: "GopherCon", Description: "He spoke about his work"},
+			},
+		}},
+	}
+
+	anonymized := utils.AnonymizeResume(resume, models.AnonymizationOptions{})
+	result := anonymized.Resume
+
+	assert.True(t, result.ID.IsZero())
+	assert.True(t, result.UserID.IsZero())
+	assert.True(t, result.LocaleGroupID.IsZero())
+	assert.Nil(t, result.Tags)
+	assert.Equal(t, utils.AnonymousName, result.Name)
+	assert.Equal(t, utils.AnonymousEmail, result.Email)
+	assert.Equal(t, utils.AnonymousPhone, result.PhoneNumber)
+	assert.Equal(t, utils.AnonymousLocation, result.Location)
+	assert.Equal(t, utils.AnonymousLink, *result.LinkedInLink)
+	assert.Equal(t, "They builds APIs", *result.Summary)
+	assert.True(t, result.Education[0].EndDate.IsZero())
+	body, err := json.Marshal(result.Education[0])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "end_date")
+	assert.NotContains(t, string(body), "0001-01-01")
+	assert.Equal(t, "State University", result.Education[0].Name)
+	assert.Equal(t, utils.AnonymousLocation, result.WorkExperience[0].Location)
+	assert.Equal(t, "They shipped their service", result.WorkExperience[0].BulletPoints[0])
+	assert.Equal(t, "Their side project", *result.Projects[0].Description)
+	assert.Equal(t, utils.AnonymousLink, *result.Projects[0].ProjectUrl)
+	assert.Equal(t, "Built it themselves", result.Projects[0].BulletPoints[0])
+	assert.Equal(t, "Earned by them in 2021", result.Certifications[0].Description)
+	assert.Equal(t, utils.AnonymousLink, result.Certifications[0].CertificateLink)
+	assert.Equal(t, "Their award", result.HonorsAwards[0].Title)
+	assert.Equal(t, "Given to them", result.HonorsAwards[0].Description)
+	assert.Equal(t, "They captained the team and helped her finish", result.Extracurriculars[0].Description)
+	assert.Equal(t, []models.PronounReview{{Field: "extracurriculars[0].description", Text: "She captained the team and helped her finish"}}, anonymized.Review)
+	entry := result.CustomSections[0].Entries[0]
+	assert.Equal(t, "Their talk", entry.Title)
+	assert.Equal(t, "They spoke about their work", entry.Description)
+
+	fields := map[string]bool{}
+	for _, substitution := range anonymized.Substitutions {
+		fields[substitution.Field] = true
+	}
+	for _, field := range []string{
+		"work_experience[0].location", "projects[0].description", "projects[0].project_url",
+		"certifications[0].certificate_link", "honors_awards[0].description",
+		"extracurriculars[0].description", "custom_sections[0].entries[0].description",
+	} {
+		assert.True(t, fields[field], field)
+	}
+
+	// The stored resume is left as it was.
+	assert.Equal(t, "Her side project", *resume.Projects[0].Description)
+	assert.Equal(t, "Austin, Texas", resume.WorkExperience[0].Location)
+
+	hidden := utils.AnonymizeResume(resume, models.AnonymizationOptions{HideColleges: true}).Resume
+	assert.Equal(t, utils.AnonymousCollege, hidden.Education[0].Name)
+	assert.Equal(t, utils.AnonymousLocation, hidden.Education[0].Location)
+}
+
+// TestCheckLocaleSync tests that entries missing from a translation are flagged
+//
+// The test compares a German resume against an English sibling that is missing
+// one work experience entry and asserts that a single issue is reported for it.
+// It then checks that undated education and project entries are matched by
+// position instead of collapsing into one.
+func TestCheckLocaleSync(t *testing.T) {
+	start := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	german := models.Resume{
+		Locale: "de",
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme", StartDate: start, BulletPoints: []string{"Entwickelte die API"}},
+			{CompanyName: "Globex", StartDate: start.AddDate(-2, 0, 0)},
+		},
+	}
+	english := models.Resume{
+		Locale: "en",
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme", StartDate: start, BulletPoints: []string{"Built the API"}},
+		},
+	}
+
+	issues := utils.CheckLocaleSync(german, english)
+
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "work_experience", issues[0].Section)
+	assert.Equal(t, "de", issues[0].PresentIn)
+	assert.Equal(t, "en", issues[0].MissingIn)
+
+	// Entries without dates or links are not collapsed into one.
+	german.WorkExperience = english.WorkExperience
+	german.Education = []models.Education{{Name: "Universität"}, {Name: "Gymnasium"}}
+	english.Education = []models.Education{{Name: "University"}}
+	german.Projects = []models.Project{{Name: "Blog"}, {Name: "CLI", BulletPoints: []string{"Schrieb es"}}}
+	english.Projects = []models.Project{{Name: "Blog"}, {Name: "CLI"}}
+
+	issues = utils.CheckLocaleSync(german, english)
+
+	if assert.Len(t, issues, 2) {
+		assert.Equal(t, "education", issues[0].Section)
+		assert.Equal(t, "entry 2", issues[0].Entry)
+		assert.Equal(t, "en", issues[0].MissingIn)
+		assert.Equal(t, "projects", issues[1].Section)
+		assert.Equal(t, "entry 2", issues[1].Entry)
+		assert.Equal(t, "1 items in de, 0 in en", issues[1].Detail)
+	}
+}
+
+// TestApplyResumeLayout tests that a layout reorders sections and hides entries
+//
+// The test puts education before work experience, hides the skills section,
+// the second job and a skill, and asserts that the rendered order and visible
+// entries follow the layout while the stored resume keeps every entry. It
+// then adds a job in front of the hidden one and checks that the same job
+// stays hidden, and that layouts naming missing entries are rejected.
+func TestApplyResumeLayout(t *testing.T) {
+	resume := models.Resume{
+		Skills:    []string{"Go"},
+		Languages: []string{"English", "Latin"},
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme"},
+			{CompanyName: "Globex"},
+		},
+	}
+	assert.True(t, utils.AssignEntryIDs(&resume))
+	assert.False(t, utils.AssignEntryIDs(&resume))
+	globex := resume.WorkExperience[1].ID.Hex()
+
+	resume.Layout = &models.ResumeLayout{
+		SectionOrder:   []string{"education", "work_experience"},
+		HiddenSections: []string{"skills"},
+		HiddenEntries:  map[string][]string{"work_experience": {globex}, "languages": {"Latin"}},
+	}
+
+	visible := utils.ApplyResumeLayout(resume)
+	order := utils.ResumeSectionOrder(resume)
+
+	assert.Equal(t, []string{"education", "work_experience"}, order[:2])
+	assert.Empty(t, visible.Skills)
+	assert.Equal(t, []string{"English"}, visible.Languages)
+	assert.Len(t, visible.WorkExperience, 1)
+	assert.Equal(t, "Acme", visible.WorkExperience[0].CompanyName)
+	assert.Len(t, resume.WorkExperience, 2)
+	assert.NoError(t, utils.ValidateResumeLayout(*resume.Layout, resume))
+
+	resume.WorkExperience = append([]models.WorkExperience{{ID: primitive.NewObjectID(), CompanyName: "Initech"}}, resume.WorkExperience...)
+	visible = utils.ApplyResumeLayout(resume)
+	assert.Len(t, visible.WorkExperience, 2)
+	assert.Equal(t, "Initech", visible.WorkExperience[0].CompanyName)
+	assert.Equal(t, "Acme", visible.WorkExperience[1].CompanyName)
+
+	missing := models.ResumeLayout{HiddenEntries: map[string][]string{"work_experience": {primitive.NewObjectID().Hex()}}}
+	assert.Error(t, utils.ValidateResumeLayout(missing, resume))
+	missing = models.ResumeLayout{HiddenEntries: map[string][]string{"skills": {"Rust"}}}
+	assert.Error(t, utils.ValidateResumeLayout(missing, resume))
+}
+
+// TestDiffResumes tests that reordered entries are aligned and rewordings are detected
+//
+// The test compares a base resume with a tailored variant whose jobs are in a
+// different order and asserts that unchanged jobs are not reported, a reworded
+// bullet is paired with its original and skill changes are listed. Entries
+// that share a company and role are then matched one to one.
+func TestDiffResumes(t *testing.T) {
+	base := models.Resume{
+		Skills: []string{"Go", "MongoDB"},
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Acme", RoleTitle: "Engineer", BulletPoints: []string{"Reduced p99 latency of the payments API by 40%"}},
+			{CompanyName: "Globex", RoleTitle: "Intern", BulletPoints: []string{"Wrote internal tools"}},
+		},
+	}
+	target := models.Resume{
+		Skills: []string{"Go", "Kubernetes"},
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Globex", RoleTitle: "Intern", BulletPoints: []string{"Wrote internal tools"}},
+			{CompanyName: "Acme", RoleTitle: "Engineer", BulletPoints: []string{"Reduced p99 latency of the payments API by 45%"}},
+		},
+	}
+
+	diff := utils.DiffResumes(base, target)
+
+	assert.Equal(t, []string{"Kubernetes"}, diff.Skills.Added)
+	assert.Equal(t, []string{"MongoDB"}, diff.Skills.Removed)
+	assert.Len(t, diff.WorkExperience, 2)
+	assert.Equal(t, models.DiffChanged, diff.WorkExperience[0].Status)
+	assert.Len(t, diff.WorkExperience[0].Bullets.Reworded, 1)
+	assert.Equal(t, models.DiffUnchanged, diff.WorkExperience[1].Status)
+
+	// Two stints in the same role are matched in order, not collapsed.
+	base.WorkExperience = []models.WorkExperience{
+		{CompanyName: "Acme", RoleTitle: "Engineer", BulletPoints: []string{"Built billing"}},
+		{CompanyName: "Acme", RoleTitle: "Engineer", BulletPoints: []string{"Built search"}},
+	}
+	target.WorkExperience = base.WorkExperience
+
+	diff = utils.DiffResumes(base, target)
+
+	assert.Len(t, diff.WorkExperience, 2)
+	assert.Equal(t, models.DiffUnchanged, diff.WorkExperience[0].Status)
+	assert.Equal(t, models.DiffUnchanged, diff.WorkExperience[1].Status)
+}
+
+// TestLocalStore_RoundTrip tests storing, reading and deleting a blob on the local filesystem
+//
+// The test writes a blob into a temporary directory, reads it back, deletes it
+// and asserts that a second read reports storage.ErrNotFound. Keys that try to
+// escape the storage root are rejected.
+func TestLocalStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Put(ctx, "users/1/documents/2", strings.NewReader("%PDF-1.7"), 8, "application/pdf"))
+
+	body, err := store.Get(ctx, "users/1/documents/2")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "%PDF-1.7", string(content))
+
+	assert.NoError(t, store.Delete(ctx, "users/1/documents/2"))
+	_, err = store.Get(ctx, "users/1/documents/2")
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	assert.Error(t, store.Put(ctx, "../outside", strings.NewReader("x"), 1, "text/plain"))
+}
+
+// TestS3Store_RoundTrip tests storing, reading and deleting a blob through the S3 client
+//
+// The test runs a stand-in S3 service that checks the Signature Version 4
+// header of every request, then writes, reads and deletes a blob whose key
+// needs escaping and asserts that a second read reports storage.ErrNotFound
+// and that a store with the wrong secret is refused.
+func TestS3Store_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validS3Signature(r, "test-secret") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	config := storage.S3Config{Endpoint: server.URL, Bucket: "crafter", AccessKeyID: "test-key", SecretAccessKey: "test-secret"}
+	store, err := storage.NewS3Store(config)
+	assert.NoError(t, err)
+
+	key := "users/1/documents/my resume.pdf"
+	assert.NoError(t, store.Put(ctx, key, strings.NewReader("%PDF-1.7"), 8, "application/pdf"))
+	assert.Contains(t, objects, "/crafter/"+key)
+
+	body, err := store.Get(ctx, key)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "%PDF-1.7", string(content))
+
+	assert.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	config.SecretAccessKey = "wrong-secret"
+	store, err = storage.NewS3Store(config)
+	assert.NoError(t, err)
+	assert.Error(t, store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"))
+}
+
+// validS3Signature checks the Signature Version 4 header of a request the
+// way an S3 service does, for the headers S3Store signs.
+func validS3Signature(r *http.Request, secret string) bool {
+	var credential, signature string
+	for _, part := range strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 "), ", ") {
+		if value, ok := strings.CutPrefix(part, "Credential="); ok {
+			credential = value
+		}
+		if value, ok := strings.CutPrefix(part, "Signature="); ok {
+			signature = value
+		}
+	}
+	scope := strings.SplitN(credential, "/", 2)
+	if len(scope) != 2 || signature == "" {
+		return false
+	}
+	fields := strings.Split(scope[1], "/")
+	if len(fields) != 4 {
+		return false
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	amzDate := r.Header.Get("X-Amz-Date")
+	canonicalRequest := r.Method + "\n" + r.URL.EscapedPath() + "\n" + r.URL.RawQuery + "\n" +
+		"host:" + r.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" + payloadHash
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope[1] + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secret)
+	for _, field := range append(fields, stringToSign) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(field))
+		key = mac.Sum(nil)
+	}
+	return hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature))
+}
+
+// TestUploadDocument_TooLarge tests that an oversize upload is reported as too large
+//
+// The test posts a file larger than the upload limit and asserts that it is
+// refused with a 413 rather than reported as a missing file.
+func TestUploadDocument_TooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/documents", controllers.UploadDocument())
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "resume.pdf")
+	file.Write(bytes.Repeat([]byte("x"), 12<<20))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/users/"+primitive.NewObjectID().Hex()+"/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+// TestBlobInstance tests that the blob store is only opened on request
+//
+// The test checks that importing the storage package leaves Blobs unset,
+// that the local backend opens in the configured directory and that a
+// misconfigured s3 backend reports an error instead of exiting.
+func TestBlobInstance(t *testing.T) {
+	assert.Nil(t, storage.Blobs)
+
+	t.Setenv("STORAGE_BACKEND", "")
+	t.Setenv("STORAGE_LOCAL_DIR", t.TempDir())
+	store, err := storage.BlobInstance()
+	assert.NoError(t, err)
+	assert.IsType(t, &storage.LocalStore{}, store)
+
+	t.Setenv("STORAGE_BACKEND", "s3")
+	t.Setenv("S3_ENDPOINT", "")
+	store, err = storage.BlobInstance()
+	assert.Error(t, err)
+	assert.Nil(t, store)
+}
+
+// TestLinkDocument tests that old resume links become link documents
+//
+// The test migrates a link to a hosted PDF and a bare site link and asserts
+// that both keep their URL, belong to the user, have no stored file and are
+// named after the file or the host.
+func TestLinkDocument(t *testing.T) {
+	userId := primitive.NewObjectID()
+	at := time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)
+
+	document := utils.LinkDocument(userId, "https://cdn.example.com/files/jane-resume.pdf", at)
+	assert.Equal(t, userId, document.UserID)
+	assert.Equal(t, "https://cdn.example.com/files/jane-resume.pdf", document.URL)
+	assert.Equal(t, "jane-resume.pdf", document.FileName)
+	assert.Empty(t, document.StorageKey)
+	assert.False(t, document.ID.IsZero())
+	assert.Equal(t, at, document.CreatedAt)
+
+	assert.Equal(t, "jane.example.com", utils.LinkDocument(userId, "https://jane.example.com/", at).FileName)
+}
+
+// TestGenerateCoverLetter tests filling a cover letter template from a resume and a job
+//
+// The test builds the merge fields from a user, a resume with two jobs and a
+// target job, renders the standard template and asserts that the current
+// job, its bullets and the skills named in the job description are used.
+// A template with an unknown field is rejected, as are templates that render
+// too much text, nest range blocks too deeply or call other templates.
+func TestGenerateCoverLetter(t *testing.T) {
+	user := models.User{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"}
+	resume := models.Resume{
+		Skills: []string{"Go", "Kubernetes", "Excel"},
+		WorkExperience: []models.WorkExperience{
+			{CompanyName: "Globex", RoleTitle: "Intern", StartDate: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{CompanyName: "Acme", RoleTitle: "Backend Engineer", IsWorking: true, StartDate: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				BulletPoints: []string{"Reduced p99 latency by 40%"}},
+		},
+	}
+	job := models.TargetJob{CompanyName: "Initech", RoleTitle: "Platform Engineer", JobDescription: "We run Go services on Kubernetes."}
+	now := time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)
+
+	fields := utils.BuildCoverLetterMergeFields(user, &resume, &job, now)
+	assert.Equal(t, "Jane Doe", fields.FullName)
+	assert.Equal(t, "Backend Engineer", fields.TopRole)
+	assert.Equal(t, "Acme", fields.TopCompany)
+	assert.Equal(t, []string{"Go", "Kubernetes"}, fields.MatchingSkills)
+
+	body, err := utils.GenerateCoverLetter(utils.CoverLetterTemplates[utils.DefaultCoverLetterTemplate], fields)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body, "May 6, 2024\n\nDear Hiring Manager at Initech,"), body)
+	assert.Contains(t, body, "the Platform Engineer position at Initech. As Backend Engineer at Acme")
+	assert.Contains(t, body, "- Reduced p99 latency by 40%\n")
+	assert.Contains(t, body, "My background in Go, Kubernetes matches")
+	assert.NotContains(t, body, "\n\n\n")
+	assert.True(t, strings.HasSuffix(body, "Sincerely,\nJane Doe\n"))
+
+	_, err = utils.GenerateCoverLetter("Dear {{.Recruiter}},", fields)
+	assert.Error(t, err)
+
+	// Templates come from users, so their output and loops are bounded.
+	fields.Skills = make([]string, 200)
+	_, err = utils.GenerateCoverLetter("{{range .Skills}}{{range $.Skills}}Go {{end}}{{end}}", fields)
+	assert.ErrorContains(t, err, "longer than")
+	_, err = utils.GenerateCoverLetter("{{range .Skills}}{{range $.Skills}}{{range $.Skills}}{{end}}{{end}}{{end}}", fields)
+	assert.ErrorContains(t, err, "nested")
+	_, err = utils.GenerateCoverLetter("{{range 1000000000}}{{end}}", fields)
+	assert.Error(t, err)
+	_, err = utils.GenerateCoverLetter(`{{define "loop"}}{{template "loop"}}{{end}}{{template "loop"}}`, fields)
+	assert.Error(t, err)
+}
+
+// TestRedactCoverLetter tests that cover letter exports follow the redaction profiles
+//
+// The test redacts a letter holding an email address, a phone number, the
+// resume's location and a year range with the public_web profile and
+// asserts that the contact details are replaced, the location is cut down
+// to the country and the dates are kept. The full profile keeps everything
+// and unknown profiles are rejected.
+func TestRedactCoverLetter(t *testing.T) {
+	resume := models.Resume{PhoneNumber: "+1 555 0100", Location: "Indiranagar, Bengaluru, Karnataka, India"}
+	letter := models.CoverLetter{
+		Title: "Initech",
+		Body: "I worked at Acme from 2019-2023.\n" +
+			"I live in Indiranagar, Bengaluru, Karnataka, India.\n" +
+			"Reach me at jane.doe@example.com, +1 555 0100 or (415) 555-0123.\n",
+	}
+
+	redacted, err := utils.RedactCoverLetter(letter, models.PublicWebProfile, &resume)
+	assert.NoError(t, err)
+	assert.Equal(t, "I worked at Acme from 2019-2023.\n"+
+		"I live in India.\n"+
+		"Reach me at [redacted], [redacted] or [redacted].\n", redacted.Body)
+	assert.Contains(t, letter.Body, "jane.doe@example.com")
+
+	full, err := utils.RedactCoverLetter(letter, models.FullProfile, &resume)
+	assert.NoError(t, err)
+	assert.Equal(t, letter.Body, full.Body)
+
+	withoutResume, err := utils.RedactCoverLetter(letter, models.PublicWebProfile, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, withoutResume.Body, "jane.doe@example.com")
+
+	_, err = utils.RedactCoverLetter(letter, "unknown", &resume)
+	assert.Error(t, err)
+
+	markdown, contentType, err := utils.RenderCoverLetter(redacted, utils.MarkdownFormat)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/markdown; charset=utf-8", contentType)
+	assert.True(t, strings.HasPrefix(string(markdown), "# Initech\n\nI worked at Acme"))
+}
+
+// TestExportCoverLetter_UnknownProfile tests the cover letter export with an unknown redaction profile
+//
+// The test asks for an export with a profile that does not exist and
+// asserts that it is refused with a 400 before the letter is loaded.
+func TestExportCoverLetter_UnknownProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.GET("/users/:user_id/cover-letters/:cover_letter_id/export", controllers.ExportCoverLetter())
+
+	path := "/users/" + primitive.NewObjectID().Hex() + "/cover-letters/" + primitive.NewObjectID().Hex() + "/export?profile=everything"
+	req, _ := http.NewRequest("GET", path, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown redaction profile")
+}
+
+// TestCreateApplication_MissingFields tests the application endpoint with missing required fields
+//
+// The test attempts to create a job application without a company name and
+// expects the response to be a 400 Bad Request with an error message.
+func TestCreateApplication_MissingFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/applications", controllers.CreateApplication())
+
+	application := models.JobApplication{
+		RoleTitle: "Backend Engineer",
+		Status:    models.StatusApplied,
+	}
+
+	jsonValue, _ := json.Marshal(application)
+	req, _ := http.NewRequest("POST", "/users/"+primitive.NewObjectID().Hex()+"/applications", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "error")
+}
+
+// TestCreateApplication_InitialStatus tests that new applications start at the beginning of the pipeline
+//
+// The test checks that only saved and applied are accepted as starting
+// statuses and that creating an application straight into the offer stage
+// is refused with a 400 before anything is stored. A backdated application
+// starts its history on its applied_at date, and a future one is refused.
+func TestCreateApplication_InitialStatus(t *testing.T) {
+	assert.True(t, utils.CanStartIn(models.StatusSaved))
+	assert.True(t, utils.CanStartIn(models.StatusApplied))
+	assert.False(t, utils.CanStartIn(models.StatusOffer))
+	assert.False(t, utils.CanStartIn(models.StatusAccepted))
+
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/applications", controllers.CreateApplication())
+
+	application := models.JobApplication{
+		CompanyName: "Acme",
+		RoleTitle:   "Backend Engineer",
+		Status:      models.StatusOffer,
+	}
+
+	jsonValue, _ := json.Marshal(application)
+	req, _ := http.NewRequest("POST", "/users/"+primitive.NewObjectID().Hex()+"/applications", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must start as saved or applied")
+
+	// A backdated application starts its history on the day it was sent,
+	// so a later rejection can still be recorded on its own date.
+	now := time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC)
+	appliedAt := now.AddDate(0, 0, -14)
+	backdated := models.JobApplication{Status: models.StatusApplied, AppliedAt: &appliedAt}
+	assert.NoError(t, utils.StartApplication(&backdated, now))
+	assert.Equal(t, []models.StatusChange{{To: models.StatusApplied, At: appliedAt}}, backdated.StatusHistory)
+	_, err := utils.TransitionApplication(&backdated, models.StatusRejected, "", appliedAt.AddDate(0, 0, 7))
+	assert.NoError(t, err)
+
+	saved := models.JobApplication{Status: models.StatusSaved}
+	assert.NoError(t, utils.StartApplication(&saved, now))
+	assert.Nil(t, saved.AppliedAt)
+	assert.Equal(t, now, saved.StatusHistory[0].At)
+
+	future := now.AddDate(0, 0, 1)
+	assert.Error(t, utils.StartApplication(&models.JobApplication{Status: models.StatusApplied, AppliedAt: &future}, now))
+}
+
+// TestTransitionApplication tests the application status state machine
+//
+// The test moves a saved application to applied, asserts that the move is
+// recorded in the history and that the applied date is set, and then checks
+// that an illegal move from applied straight to accepted and a move dated
+// before the previous one are rejected.
+func TestTransitionApplication(t *testing.T) {
+	application := models.JobApplication{Status: models.StatusSaved}
+	at := time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)
+
+	change, err := utils.TransitionApplication(&application, models.StatusApplied, "sent via referral", at)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusSaved, change.From)
+	assert.Equal(t, models.StatusApplied, application.Status)
+	assert.Len(t, application.StatusHistory, 1)
+	assert.Equal(t, at, *application.AppliedAt)
+
+	_, err = utils.TransitionApplication(&application, models.StatusAccepted, "", at)
+	assert.Error(t, err)
+	assert.Equal(t, models.StatusApplied, application.Status)
+
+	_, err = utils.TransitionApplication(&application, models.StatusRejected, "", at.Add(-time.Hour))
+	assert.Error(t, err)
+	assert.Equal(t, models.StatusApplied, application.Status)
+	assert.Len(t, application.StatusHistory, 1)
+
+	_, err = utils.TransitionApplication(&application, models.StatusRejected, "", at)
+	assert.NoError(t, err)
+	assert.Equal(t, at, utils.LastStatusChange(application))
+}
+
+// TestBoardPosition tests placing a card between its neighbours on the board
+//
+// The test drops cards at the top, in the middle and past the end of a
+// column, and checks that a column without room between two cards asks to
+// be renumbered.
+func TestBoardPosition(t *testing.T) {
+	positions := []float64{1024, 2048, 3072}
+
+	top, ok := utils.BoardPosition(positions, 0)
+	assert.True(t, ok)
+	assert.Less(t, top, positions[0])
+
+	middle, ok := utils.BoardPosition(positions, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 1536.0, middle)
+
+	bottom, ok := utils.BoardPosition(positions, 10)
+	assert.True(t, ok)
+	assert.Greater(t, bottom, positions[2])
+
+	_, ok = utils.BoardPosition([]float64{1, math.Nextafter(1, 2)}, 1)
+	assert.False(t, ok)
+}
+
+// TestDueReminders tests that follow-ups are only suggested once they are due
+//
+// The test checks an application applied to eight days ago and one applied
+// to yesterday, and asserts that the reminder key stays the same between
+// scans so a stored reminder is never created twice. Applications whose
+// follow-up came due long ago, like old ones seen on first start or after
+// an import, get no reminder.
+func TestDueReminders(t *testing.T) {
+	now := time.Date(2024, time.June, 10, 12, 0, 0, 0, time.UTC)
+	longAgo := now.Add(-8 * 24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+	lastMonth := now.Add(-30 * 24 * time.Hour)
+
+	stale := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Acme", RoleTitle: "Engineer", Status: models.StatusApplied, AppliedAt: &longAgo}
+	recent := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Acme", RoleTitle: "Engineer", Status: models.StatusApplied, AppliedAt: &yesterday}
+
+	reminders := utils.DueReminders(stale, now)
+	assert.Len(t, reminders, 1)
+	assert.Equal(t, models.ReminderNoResponse, reminders[0].Kind)
+	assert.Equal(t, reminders[0].Key, utils.DueReminders(stale, now.Add(time.Hour))[0].Key)
+
+	assert.Empty(t, utils.DueReminders(recent, now))
+
+	old := models.JobApplication{ID: primitive.NewObjectID(), Status: models.StatusApplied, AppliedAt: &lastMonth}
+	assert.Empty(t, utils.DueReminders(old, now))
+
+	interviewing := models.JobApplication{
+		ID:            primitive.NewObjectID(),
+		Status:        models.StatusInterviewing,
+		StatusHistory: []models.StatusChange{{To: models.StatusInterviewing, At: yesterday.Add(-time.Hour)}},
+	}
+	assert.Len(t, utils.DueReminders(interviewing, now), 1)
+	interviewing.StatusHistory[0].At = lastMonth
+	assert.Empty(t, utils.DueReminders(interviewing, now))
+}
+
+// TestLogNotifier tests that logged reminders carry no contact details
+//
+// The test sends a reminder through the log notifier and asserts that the
+// log line names the user by ID and leaves out their email address.
+func TestLogNotifier(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	user := models.User{ID: primitive.NewObjectID(), Email: "jane@example.com"}
+	err := reminders.LogNotifier{}.Notify(context.Background(), user, models.Reminder{Message: "Follow up with Acme"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, logged.String(), user.ID.Hex())
+	assert.Contains(t, logged.String(), "Follow up with Acme")
+	assert.NotContains(t, logged.String(), "jane@example.com")
+}
+
+// TestRenderInterviewCalendar tests the iCalendar export of interview rounds
+//
+// The test renders one round with a long prep note and checks that the
+// event is written in UTC, that text values are escaped and that no line
+// is longer than the 75 octets allowed by RFC 5545.
+func TestRenderInterviewCalendar(t *testing.T) {
+	interview := models.Interview{
+		ID:              primitive.NewObjectID(),
+		Round:           "System design",
+		StartsAt:        time.Date(2024, time.June, 10, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		TimeZone:        "Europe/Berlin",
+		DurationMinutes: 60,
+		Format:          models.VideoInterview,
+		PrepNote:        strings.Repeat("Review caching, queues; sharding. ", 10),
+	}
+	assert.NoError(t, utils.ValidateInterview(&interview))
+
+	application := models.JobApplication{CompanyName: "Acme", RoleTitle: "Engineer", Interviews: []models.Interview{interview}}
+	calendar := string(utils.RenderInterviewCalendar("Interviews", []models.JobApplication{application}))
+
+	assert.Contains(t, calendar, "DTSTART:20240610T120000Z\r\n")
+	assert.Contains(t, calendar, "DTEND:20240610T130000Z\r\n")
+	assert.Contains(t, calendar, `queues\; sharding`)
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	interview.TimeZone = "Mars/Olympus"
+	assert.Error(t, utils.ValidateInterview(&interview))
+}
+
+// TestCreateContact_InvalidEmail tests the contact endpoint with a malformed email address
+//
+// The test attempts to create a contact whose email address is not valid
+// and expects the response to be a 400 Bad Request with an error message.
+func TestCreateContact_InvalidEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/contacts", controllers.CreateContact())
+
+	contact := models.Contact{
+		Name:    "Jordan Lee",
+		Company: "Acme",
+		Email:   "jordan.at.acme",
+	}
+
+	jsonValue, _ := json.Marshal(contact)
+	req, _ := http.NewRequest("POST", "/users/"+primitive.NewObjectID().Hex()+"/contacts", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "error")
+}
+
+// TestBuildTimeline tests merging an application's activity into one feed
+//
+// The test combines a status change, a note and a contact interaction and
+// asserts that the feed is ordered newest first and names the contact.
+func TestBuildTimeline(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.May, d, 9, 0, 0, 0, time.UTC) }
+	contact := models.Contact{ID: primitive.NewObjectID(), Name: "Jordan Lee"}
+
+	events := utils.BuildTimeline(utils.TimelineSources{
+		Application: models.JobApplication{StatusHistory: []models.StatusChange{
+			{To: models.StatusSaved, At: day(1)},
+			{From: models.StatusSaved, To: models.StatusApplied, At: day(3)},
+		}},
+		Notes:        []models.ApplicationNote{{ID: primitive.NewObjectID(), Body: "Ask about **on-call**", CreatedAt: day(2)}},
+		Interactions: []models.Interaction{{ID: primitive.NewObjectID(), ContactID: contact.ID, Kind: models.CoffeeChatInteraction, OccurredAt: day(4)}},
+		Contacts:     []models.Contact{contact},
+	})
+
+	assert.Len(t, events, 4)
+	assert.Equal(t, "Coffee chat with Jordan Lee", events[0].Title)
+	assert.Equal(t, "Moved from saved to applied", events[1].Title)
+	assert.Equal(t, models.NoteEvent, events[2].Kind)
+	assert.Equal(t, "Created as saved", events[3].Title)
+}
+
+// TestParseJobPosting tests extracting a job posting from a page's JSON-LD
+//
+// The test parses a page that embeds a schema.org JobPosting inside an
+// @graph and checks the title, company, location, salary and description,
+// then parses a page without markup and expects the title and company to
+// be taken from the page title.
+func TestParseJobPosting(t *testing.T) {
+	page := `<html><head><title>Careers</title>
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"WebPage"},{
+	"@type":"JobPosting","title":"Backend Engineer","datePosted":"2024-05-01",
+	"hiringOrganization":{"@type":"Organization","name":"Acme"},
+	"jobLocation":{"@type":"Place","address":{"addressLocality":"Berlin","addressCountry":"DE"}},
+	"baseSalary":{"@type":"MonetaryAmount","currency":"EUR","value":{"@type":"QuantitativeValue","minValue":70000,"maxValue":90000,"unitText":"YEAR"}},
+	"description":"&lt;p&gt;Build APIs.&lt;/p&gt;&lt;ul&gt;&lt;li&gt;Go&lt;/li&gt;&lt;li&gt;MongoDB&lt;/li&gt;&lt;/ul&gt;"}]}</script>
+</head><body></body></html>`
+
+	job, err := utils.ParseJobPosting(page)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JSONLDJobSource, job.Source)
+	assert.Equal(t, "Backend Engineer", job.Title)
+	assert.Equal(t, "Acme", job.CompanyName)
+	assert.Equal(t, "Berlin, DE", job.Location)
+	assert.Equal(t, &models.SalaryRange{Min: 70000, Max: 90000, Currency: "EUR", Period: "year"}, job.Salary)
+	assert.Equal(t, "Build APIs.\n\n- Go\n- MongoDB", job.Description)
+
+	job, err = utils.ParseJobPosting(`<html><head><title>Data Analyst at Globex | Careers</title></head><body><p>Apply now</p></body></html>`)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HeuristicJobSource, job.Source)
+	assert.Equal(t, "Data Analyst", job.Title)
+	assert.Equal(t, "Globex", job.CompanyName)
+}
+
+// TestCheckRequirements tests the requirement checklist against a resume
+//
+// The test extracts requirements from a description with a years of
+// experience bar, a degree, must-have and nice-to-have skills, and checks
+// which of them a resume with four years of experience meets.
+func TestCheckRequirements(t *testing.T) {
+	description := `About the role
+You will build services in Go.
+
+Requirements:
+- 5+ years of experience building backend systems
+- BS in Computer Science or equivalent experience
+- Strong Kubernetes and PostgreSQL skills
+
+Nice to have:
+- Experience with Kafka
+
+Benefits:
+- Python lunch club`
+
+	requirements := utils.ExtractRequirements("Senior Backend Engineer", description, nil)
+	assert.Equal(t, 5, requirements.MinYearsExperience)
+	assert.Equal(t, models.BachelorDegree, requirements.Degree)
+	assert.False(t, requirements.DegreeRequired)
+	assert.Equal(t, []string{"Go", "Kubernetes", "PostgreSQL"}, requirements.MustHaveSkills)
+	assert.Equal(t, []string{"Kafka"}, requirements.NiceToHaveSkills)
+	assert.Equal(t, models.SeniorSeniority, requirements.Seniority)
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	resume := models.Resume{
+		Skills:    []string{"Golang", "PostgreSQL"},
+		Education: []models.Education{{Name: "B.Tech in Computer Science"}},
+		WorkExperience: []models.WorkExperience{
+			{StartDate: now.AddDate(-4, 0, 0), IsWorking: true, BulletPoints: []string{"Ran services on Kubernetes"}},
+			{StartDate: now.AddDate(-3, 0, 0), EndDate: now.AddDate(-2, 0, 0)},
+		},
+	}
+
+	report := utils.CheckRequirements(requirements, resume, now)
+	status := map[string]models.RequirementStatus{}
+	for _, check := range report.Checklist {
+		status[check.Requirement] = check.Status
+	}
+	assert.Equal(t, 4.0, report.ResumeYears)
+	assert.Equal(t, models.RequirementPartial, status["5+ years of experience"])
+	assert.Equal(t, models.RequirementMet, status["bachelor degree"])
+	assert.Equal(t, models.RequirementMet, status["Go"])
+	assert.Equal(t, models.RequirementPartial, status["Kubernetes"])
+	assert.Equal(t, models.RequirementMissing, status["Kafka"])
+}
+
+// TestScoreJobFit tests ranking saved jobs by fit against a resume
+//
+// The test scores a mid-level job in the resume's country and a remote
+// lead role that asks for a missing skill, and checks the factor scores,
+// the explanations and that the closer fit ranks first.
+func TestScoreJobFit(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	resume := models.Resume{
+		Skills:   []string{"Golang", "PostgreSQL"},
+		Location: "Berlin, Germany",
+	}
+	nearby := models.SavedJob{
+		ID:          primitive.NewObjectID(),
+		Title:       "Mid-level Backend Engineer",
+		Location:    "Munich, Germany",
+		Description: "Requirements:\n- Experience with Go and PostgreSQL",
+		CreatedAt:   now.Add(-time.Hour),
+	}
+	stretch := models.SavedJob{
+		ID:          primitive.NewObjectID(),
+		Title:       "Staff Engineer",
+		Remote:      true,
+		Description: "Requirements:\n- Experience with Rust and Go",
+		CreatedAt:   now,
+	}
+
+	nearbyFit := utils.ScoreJobFit(nearby, resume, models.MidLevel, now)
+	assert.Equal(t, 94, nearbyFit.Score)
+	assert.Equal(t, []string{"Go", "PostgreSQL"}, nearbyFit.MatchedSkills)
+	assert.Equal(t, 0.6, nearbyFit.Components[2].Score)
+
+	stretchFit := utils.ScoreJobFit(stretch, resume, models.MidLevel, now)
+	assert.Equal(t, 48, stretchFit.Score)
+	assert.Equal(t, []string{"Rust"}, stretchFit.MissingSkills)
+	assert.Contains(t, stretchFit.Components[0].Explanation, "missing Rust")
+	assert.Contains(t, stretchFit.Components[1].Explanation, "well above")
+	assert.Equal(t, "remote role", stretchFit.Components[2].Explanation)
+
+	ranked := utils.RankJobFits([]models.SavedJob{stretch, nearby}, map[string]models.JobFit{
+		nearby.ID.Hex():  nearbyFit,
+		stretch.ID.Hex(): stretchFit,
+	})
+	assert.Equal(t, nearby.ID, ranked[0].Job.ID)
+	assert.Equal(t, 2, ranked[1].Rank)
+}
+
+// TestBuildFunnel tests the funnel conversion rates and the median
+//
+// The test builds a funnel from the number of applications reaching each
+// stage, checks the conversion between consecutive stages including an
+// empty one, and takes the median of odd and even length samples.
+func TestBuildFunnel(t *testing.T) {
+	report := utils.BuildFunnel([]int{20, 8, 4, 1, 0})
+	assert.Len(t, report.Stages, len(models.FunnelStages))
+	assert.Len(t, report.Conversions, len(models.FunnelStages)-1)
+	assert.Equal(t, models.StatusApplied, report.Conversions[0].From)
+	assert.Equal(t, models.StatusScreening, report.Conversions[0].To)
+	assert.Equal(t, 0.4, report.Conversions[0].Rate)
+	assert.Equal(t, 0.25, report.Conversions[2].Rate)
+	assert.Equal(t, 0.0, report.Conversions[3].Rate)
+	assert.Equal(t, 0.0, utils.BuildFunnel(nil).Conversions[0].Rate)
+
+	median, ok := utils.Median([]float64{30, 2, 12})
+	assert.True(t, ok)
+	assert.Equal(t, 12.0, median)
+	median, _ = utils.Median([]float64{48, 2, 12, 30})
+	assert.Equal(t, 21.0, median)
+	_, ok = utils.Median(nil)
+	assert.False(t, ok)
+}
+
+// TestParseImportCSV tests reading a tracker spreadsheet for import
+//
+// The test reads a semicolon separated file with a byte order mark and
+// tracker-style headers, checks the suggested mapping, parses rows with
+// mixed date formats, statuses and salaries, and exports the result again
+// with the stable header.
+func TestParseImportCSV(t *testing.T) {
+	file := "\xef\xbb\xbfCompany;Position;Stage;Date Applied;Salary;Notes\n" +
+		"Acme;Backend Engineer;Phone Screen;25/03/2024;$120k - $140k;Referred by Sam\n" +
+		"\n" +
+		"Globex;Data Engineer;;Mar 5, 2024;;\n" +
+		"Initech;QA;Maybe;2024-02-30;100000;\n"
+
+	headers, records, err := utils.ReadCSV(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Company", "Position", "Stage", "Date Applied", "Salary", "Notes"}, headers)
+	assert.Len(t, records, 3)
+	assert.Equal(t, 4, records[1].Line)
+
+	mapping := utils.SuggestImportMapping(headers)
+	assert.Equal(t, "Company", mapping[models.ImportCompanyName])
+	assert.Equal(t, "Position", mapping[models.ImportRoleTitle])
+	assert.Equal(t, "Date Applied", mapping[models.ImportAppliedAt])
+	assert.NoError(t, utils.CheckImportMapping(mapping, headers))
+	assert.Empty(t, utils.MissingImportFields(mapping))
+
+	dayFirst, decided := utils.DetectDayFirst([]string{"03/04/2024", "25/03/2024"})
+	assert.True(t, dayFirst)
+	assert.True(t, decided)
+
+	acme := utils.ParseImportRecord(headers, records[0], mapping, dayFirst)
+	assert.Empty(t, acme.Errors)
+	assert.Equal(t, models.StatusScreening, acme.Application.Status)
+	assert.Equal(t, time.Date(2024, time.March, 25, 0, 0, 0, 0, time.UTC), *acme.Application.AppliedAt)
+	assert.Equal(t, &models.SalaryRange{Min: 120000, Max: 140000, Currency: "USD", Period: "year"}, acme.Application.Salary)
+	assert.Equal(t, "Referred by Sam", acme.Note)
+
+	globex := utils.ParseImportRecord(headers, records[1], mapping, dayFirst)
+	assert.Empty(t, globex.Errors)
+	assert.Equal(t, models.StatusApplied, globex.Application.Status)
+	assert.Equal(t, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), *globex.Application.AppliedAt)
+
+	initech := utils.ParseImportRecord(headers, records[2], mapping, dayFirst)
+	fields := []models.ImportField{}
+	for _, rowError := range initech.Errors {
+		fields = append(fields, rowError.Field)
+	}
+	assert.ElementsMatch(t, []models.ImportField{models.ImportAppliedAt, models.ImportStatus, models.ImportSalaryCurrency}, fields)
+
+	assert.Equal(t,
+		utils.ApplicationDuplicateKey("Acme ", "backend  engineer"),
+		utils.ApplicationDuplicateKey("ACME", "Backend Engineer"))
+
+	var out bytes.Buffer
+	acme.Application.CompanyName = "=Acme"
+	assert.NoError(t, utils.WriteApplicationsCSV(&out, []models.JobApplication{acme.Application}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, strings.Join(models.ApplicationCSVColumns, ","), lines[0])
+	assert.Contains(t, lines[1], ",'=Acme,Backend Engineer,screening,2024-03-25T00:00:00Z,")
+}
+
+// TestPreviewApplicationImport_TooLarge tests that an oversize import is reported as too large
+//
+// The test posts a CSV file larger than the import limit and asserts that it
+// is refused with a 413 rather than reported as a missing file.
+func TestPreviewApplicationImport_TooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.POST("/users/:user_id/applications/import/preview", controllers.PreviewApplicationImport())
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "applications.csv")
+	file.Write(bytes.Repeat([]byte("x"), 7<<20))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/users/"+primitive.NewObjectID().Hex()+"/applications/import/preview", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+// TestEmailProposals tests classifying uploaded emails and matching them
+//
+// The test splits an mbox archive holding a rejection sent through an
+// applicant tracking system and a multipart interview invite from the
+// company's own domain, and checks the kind, proposed status and matched
+// application of each, and the path a saved application takes.
+func TestEmailProposals(t *testing.T) {
+	archive := "From greenhouse Mon Mar  4 10:00:00 2024\n" +
+		"From: Acme Recruiting <no-reply@greenhouse-mail.io>\n" +
+		"Subject: Your application to Acme\n" +
+		"Message-ID: <r1@greenhouse-mail.io>\n" +
+		"Date: Mon, 4 Mar 2024 10:00:00 +0000\n" +
+		"\n" +
+		"Thank you for applying for the Backend Engineer role.\n" +
+		">From our side, we have decided not to move forward.\n" +
+		"\n" +
+		"From jane Tue Mar  5 09:00:00 2024\n" +
+		"From: =?UTF-8?Q?Jane_M=C3=BCller?= <jane@careers.acmecorp.com>\n" +
+		"Subject: Next steps\n" +
+		"Date: Tue, 5 Mar 2024 09:00:00 +0000\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: multipart/alternative; boundary=\"b1\"\n" +
+		"\n" +
+		"--b1\n" +
+		"Content-Type: text/html; charset=utf-8\n" +
+		"Content-Transfer-Encoding: quoted-printable\n" +
+		"\n" +
+		"<p>We would like to schedule an interview for the Data Engineer =\n" +
+		"position.</p>\n" +
+		"--b1--\n"
+
+	messages, problems := utils.ParseEmails([]byte(archive))
+	assert.Empty(t, problems)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "r1@greenhouse-mail.io", messages[0].MessageID)
+	assert.Contains(t, messages[0].Text, "From our side")
+	assert.Equal(t, "Jane Müller", messages[1].FromName)
+	assert.Contains(t, messages[1].Text, "Data Engineer position")
+	assert.NotEmpty(t, messages[1].MessageID)
+
+	backend := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Acme Corp", RoleTitle: "Backend Engineer", Status: models.StatusSaved}
+	data := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Acme Corp", RoleTitle: "Data Engineer", Status: models.StatusApplied}
+	other := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Globex", RoleTitle: "Backend Engineer", Status: models.StatusApplied}
+	applications := []models.JobApplication{backend, data, other}
+
+	kind, status, cues := utils.ClassifyEmail(messages[0])
+	assert.Equal(t, models.RejectionEmail, kind)
+	assert.Equal(t, models.StatusRejected, status)
+	assert.Equal(t, []string{"not to move forward"}, cues)
+	match := utils.MatchEmailApplication(messages[0], applications)
+	if assert.NotNil(t, match.Application) {
+		assert.Equal(t, backend.ID, match.Application.ID)
+	}
+
+	kind, status, _ = utils.ClassifyEmail(messages[1])
+	assert.Equal(t, models.InterviewEmail, kind)
+	assert.Equal(t, models.StatusInterviewing, status)
+	match = utils.MatchEmailApplication(messages[1], applications)
+	if assert.NotNil(t, match.Application) {
+		assert.Equal(t, data.ID, match.Application.ID)
+	}
+
+	path, ok := utils.TransitionPath(models.StatusSaved, models.StatusRejected)
+	assert.True(t, ok)
+	assert.Equal(t, []models.ApplicationStatus{models.StatusApplied, models.StatusRejected}, path)
+	_, ok = utils.TransitionPath(models.StatusInterviewing, models.StatusApplied)
+	assert.False(t, ok)
+
+	kind, _, _ = utils.ClassifyEmail(models.EmailMessage{Subject: "Your weekly newsletter"})
+	assert.Equal(t, models.UnknownEmail, kind)
+}
+
+// TestCompareOffers tests normalising offers for comparison
+//
+// The test compares a USD offer with even vesting and a cliff against a EUR
+// offer with a back-loaded grant and options priced in USD, and checks the
+// year one, four-year average and per-hour figures, the ordering and the
+// errors for a bad schedule and a missing exchange rate.
+func TestCompareOffers(t *testing.T) {
+	even := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Acme Corp", RoleTitle: "Backend Engineer", Offer: &models.Offer{
+		Currency:     "usd",
+		BaseSalary:   150000,
+		BonusPercent: 10,
+		SignOnBonus:  20000,
+		Equity:       []models.EquityGrant{{Type: models.RSUEquity, Shares: 400, PricePerShare: 100, VestingYears: 4, CliffMonths: 12}},
+		Benefits:     []models.OfferBenefit{{Name: "Health", AnnualValue: 5000}},
+	}}
+	backLoaded := models.JobApplication{ID: primitive.NewObjectID(), CompanyName: "Globex", RoleTitle: "Data Engineer", Offer: &models.Offer{
+		Currency:   "EUR",
+		BaseSalary: 120000,
+		Equity: []models.EquityGrant{
+			{Type: models.RSUEquity, GrantValue: 80000, VestingYears: 4, YearlyPercent: []float64{5, 15, 40, 40}},
+			{Type: models.OptionEquity, Shares: 1000, PricePerShare: 30, StrikePrice: 10, Currency: "USD", VestingYears: 4, CliffMonths: 18},
+		},
+	}}
+	for _, application := range []models.JobApplication{even, backLoaded} {
+		assert.NoError(t, utils.ValidateOffer(application.Offer))
+	}
+	assert.Equal(t, "USD", even.Offer.Currency)
+
+	vesting := utils.VestingByYear(backLoaded.Offer.Equity[1], 4)
+	assert.Equal(t, []float64{0, 0.5, 0.25, 0.25}, vesting)
+
+	comparison, err := utils.CompareOffers([]models.JobApplication{backLoaded, even}, "usd", map[string]float64{"eur": 1.1})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", comparison.Currency)
+	if assert.Len(t, comparison.Offers, 2) {
+		first, second := comparison.Offers[0], comparison.Offers[1]
+		assert.Equal(t, even.ID, first.ApplicationID)
+		assert.Equal(t, 200000.0, first.Year1.Total)
+		assert.Equal(t, 10000.0, first.Year1.Equity)
+		assert.Equal(t, 185000.0, first.FourYearAverage.Total)
+		assert.Equal(t, 5000.0, first.FourYearAverage.SignOn)
+		assert.Equal(t, 96.15, first.Year1PerHour)
+
+		assert.Equal(t, backLoaded.ID, second.ApplicationID)
+		assert.Equal(t, 136400.0, second.Year1.Total)
+		assert.Equal(t, 159000.0, second.FourYearAverage.Total)
+		assert.Equal(t, []float64{136400, 155200, 172200, 172200}, second.YearlyTotals)
+		assert.NotEmpty(t, second.Notes)
+	}
+
+	_, err = utils.CompareOffers([]models.JobApplication{backLoaded}, "GBP", map[string]float64{"EUR": 0.85})
+	assert.ErrorContains(t, err, "no exchange rate for USD")
+
+	bad := models.Offer{Currency: "USD", BaseSalary: 1, Equity: []models.EquityGrant{{Type: models.RSUEquity, GrantValue: 1, VestingYears: 2, YearlyPercent: []float64{50, 40}}}}
+	assert.ErrorContains(t, utils.ValidateOffer(&bad), "adds up to 90")
+}
+
+// TestSavedFilters tests tag normalisation and applying saved filters
+//
+// The test normalises tags, resolves the relative dates saved filters use,
+// rejects parameters a list does not understand and merges a saved filter
+// with the query of a list request.
+func TestSavedFilters(t *testing.T) {
+	assert.Equal(t, []string{"remote", "backend", "big tech"}, utils.NormalizeTags([]string{" Remote", "backend", "", "REMOTE", "Big   Tech"}))
+	assert.Nil(t, utils.NormalizeTags(nil))
+	assert.Equal(t, []string{"remote", "backend"}, utils.ParseTagList("remote, Backend,,"))
+	assert.Nil(t, utils.ParseTagList(" "))
+
+	now := time.Date(2024, time.March, 14, 15, 30, 0, 0, time.UTC)
+	for value, expected := range map[string]time.Time{
+		"today":      time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC),
+		"this_week":  time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC),
+		"this_month": time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		"this_year":  time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"30d":        time.Date(2024, time.February, 13, 0, 0, 0, 0, time.UTC),
+	} {
+		date, ok := utils.RelativeDate(value, now)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, date, value)
+	}
+	_, ok := utils.RelativeDate("2024-03-01", now)
+	assert.False(t, ok)
+
+	filter := models.SavedFilter{
+		Name:   "Remote backend this month",
+		Target: models.ApplicationTarget,
+		Tags:   []string{"remote", "backend"},
+		Query:  map[string]string{"status": "applied", "applied_from": "this_month"},
+	}
+	assert.NoError(t, utils.CheckSavedFilter(filter))
+	assert.ErrorContains(t, utils.CheckSavedFilter(models.SavedFilter{Target: models.SavedJobTarget, Query: map[string]string{"status": "applied"}}), "status cannot filter saved_jobs")
+
+	query := utils.ApplySavedFilter(filter, url.Values{"status": {"interviewing"}, "tags": {"Fintech,remote"}, "page": {"2"}})
+	assert.Equal(t, "interviewing", query.Get("status"))
+	assert.Equal(t, "this_month", query.Get("applied_from"))
+	assert.Equal(t, "remote,backend,fintech", query.Get("tags"))
+	assert.Equal(t, "2", query.Get("page"))
+}
+
+// TestListEndpoints_FilterID tests that every tagged list reads the filter_id parameter
+//
+// The test calls each list endpoint that accepts saved filters with a
+// malformed filter_id and asserts that all of them reject it with a 400,
+// which shows the saved filter is looked up rather than ignored.
+func TestListEndpoints_FilterID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	router.GET("/users/:user_id/applications", controllers.GetApplications())
+	router.GET("/users/:user_id/contacts", controllers.GetContacts())
+	router.GET("/users/:user_id/contacts/stale", controllers.GetStaleContacts())
+	router.GET("/users/:user_id/saved-jobs", controllers.GetSavedJobs())
+	router.GET("/users/:user_id/resumes", controllers.GetResumes())
+
+	userPath := "/users/" + primitive.NewObjectID().Hex()
+	for _, path := range []string{"/applications", "/contacts", "/contacts/stale", "/saved-jobs", "/resumes"} {
+		req, _ := http.NewRequest("GET", userPath+path+"?filter_id=nope", nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.Contains(t, w.Body.String(), "Invalid filter_id", path)
+	}
+}
+
+// TestAuthenticate tests the authentication middleware
+//
+// The test lets public routes through without a token, refuses missing,
+// forged, expired and refresh tokens, and only serves routes under
+// /users/:user_id to the user the token was issued to. Unknown paths still
+// answer 404.
+func TestAuthenticate(t *testing.T) {
+	secret := utils.SECRET_KEY
+	utils.SECRET_KEY = "test-secret"
+	defer func() { utils.SECRET_KEY = secret }()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Authenticate())
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"uid": c.GetString("uid"), "email": c.GetString("email")})
+	}
+	router.POST("/users/login", ok)
+	router.GET("/shared/resumes/:token", ok)
+	router.GET("/users", ok)
+	router.PUT("/users/:user_id", ok)
+	router.GET("/users/:user_id/applications", ok)
+
+	userId := primitive.NewObjectID().Hex()
+	token, refreshToken, err := utils.GenerateAllTokens("jane@example.com", "Jane", "Doe", userId)
+	assert.NoError(t, err)
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.SignedDetails{
+		Uid:            userId,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}).SignedString([]byte("another-secret"))
+	expired, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.SignedDetails{
+		Uid:            userId,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+	}).SignedString([]byte("test-secret"))
+
+	request := func(method string, path string, header string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("POST", "/users/login", "").Code)
+	assert.Equal(t, http.StatusOK, request("GET", "/shared/resumes/abc", "").Code)
+
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users", token).Code)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users", "Bearer "+forged).Code)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users", "Bearer not-a-token").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("GET", "/users", "Bearer "+refreshToken).Code)
+	w := request("GET", "/users", "Bearer "+expired)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "token is expired")
+
+	w = request("GET", "/users/"+userId+"/applications", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, userId, body["uid"])
+	assert.Equal(t, "jane@example.com", body["email"])
+
+	other := primitive.NewObjectID().Hex()
+	assert.Equal(t, http.StatusForbidden, request("PUT", "/users/"+other, "Bearer "+token).Code)
+	assert.Equal(t, http.StatusForbidden, request("GET", "/users/"+other+"/applications", "Bearer "+token).Code)
+
+	assert.Equal(t, http.StatusNotFound, request("GET", "/no/such/path", "").Code)
+	assert.Equal(t, http.StatusNotFound, request("GET", "/no/such/path", "Bearer "+token).Code)
+}
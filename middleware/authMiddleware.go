@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crafter/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicRoutes can be used without signing in. Routes under /shared/ are
+// public too: the token in their URL is what grants access.
+var publicRoutes = map[string]bool{
+	"/users/signup": true,
+	"/users/login":  true,
+}
+
+func abortWithError(c *gin.Context, statusCode int, errMessage string) {
+	c.AbortWithStatusJSON(statusCode, gin.H{
+		"status":  "error",
+		"message": errMessage,
+	})
+}
+
+// Authenticate requires a valid bearer token on every route that is not
+// public and stores its uid and email claims in the context. Routes under
+// /users/:user_id only serve the user the token was issued to. Paths that
+// match no route are left to the router, so they stay 404s.
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" || publicRoutes[path] || strings.HasPrefix(path, "/shared/") {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		scheme, clientToken, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(clientToken) == "" {
+			abortWithError(c, http.StatusUnauthorized, "Authorization header with a bearer token is required")
+			return
+		}
+
+		claims, msg := utils.ValidateToken(strings.TrimSpace(clientToken))
+		if msg != "" {
+			abortWithError(c, http.StatusUnauthorized, msg)
+			return
+		}
+		// Refresh tokens carry no user and cannot be used as access tokens.
+		if claims.Uid == "" {
+			abortWithError(c, http.StatusUnauthorized, "the token is invalid")
+			return
+		}
+
+		if userId := c.Param("user_id"); userId != "" && userId != claims.Uid {
+			abortWithError(c, http.StatusForbidden, "you can only access your own account")
+			return
+		}
+
+		c.Set("uid", claims.Uid)
+		c.Set("email", claims.Email)
+		c.Next()
+	}
+}
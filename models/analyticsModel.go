@@ -0,0 +1,67 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// FunnelStages are the statuses of the application funnel in order. An
+// application counts towards every stage up to the furthest one it reached,
+// even when it skipped some on the way.
+var FunnelStages = []ApplicationStatus{
+	StatusApplied, StatusScreening, StatusInterviewing, StatusOffer, StatusAccepted,
+}
+
+// ResponseStatuses are the statuses that mean the company replied.
+var ResponseStatuses = []ApplicationStatus{
+	StatusScreening, StatusInterviewing, StatusOffer, StatusRejected,
+}
+
+type StageCount struct {
+	Status ApplicationStatus `json:"status"`
+	Count  int               `json:"count"`
+}
+
+// StageConversion is the share of applications that reached From and went
+// on to reach To.
+type StageConversion struct {
+	From      ApplicationStatus `json:"from"`
+	To        ApplicationStatus `json:"to"`
+	Reached   int               `json:"reached"`
+	Converted int               `json:"converted"`
+	Rate      float64           `json:"rate"`
+}
+
+type FunnelReport struct {
+	Stages      []StageCount      `json:"stages"`
+	Conversions []StageConversion `json:"conversions"`
+}
+
+type ResponseTimeReport struct {
+	Applied   int `json:"applied"`
+	Responded int `json:"responded"`
+	// MedianHours is nil until at least one application got a reply.
+	MedianHours *float64 `json:"median_hours"`
+}
+
+// ResponseRate is the reply rate of a group of applications, such as those
+// from one source or sent with one resume variant.
+type ResponseRate struct {
+	Applications int     `bson:"applications" json:"applications"`
+	Responses    int     `bson:"responses" json:"responses"`
+	Interviews   int     `bson:"interviews" json:"interviews"`
+	Rate         float64 `bson:"-" json:"rate"`
+}
+
+type SourceResponseRate struct {
+	Source       string `bson:"_id" json:"source"`
+	ResponseRate `bson:",inline"`
+}
+
+// ResumeVariantResult groups applications by the resume or uploaded
+// document they were sent with. Both ids are nil for applications sent
+// without one.
+type ResumeVariantResult struct {
+	ResumeID     *primitive.ObjectID `bson:"resume_id" json:"resume_id"`
+	Locale       string              `bson:"locale,omitempty" json:"locale,omitempty"`
+	DocumentID   *primitive.ObjectID `bson:"document_id" json:"document_id"`
+	FileName     string              `bson:"file_name,omitempty" json:"file_name,omitempty"`
+	ResponseRate `bson:",inline"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// AnonymizationOptions controls the optional parts of blind-hiring
+// anonymization. Name, contact details, every link, work locations,
+// gendered pronouns, graduation dates and the resume's ids and tags are
+// always removed.
+type AnonymizationOptions struct {
+	HideColleges bool `json:"hide_colleges"`
+}
+
+// Substitution records a single value that was replaced while anonymizing a
+// resume. Field is the JSON path of the value, e.g. "work_experience[0].bullet_points[1]".
+type Substitution struct {
+	Field       string `json:"field"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+}
+
+// PronounReview points at a field that still holds a gendered pronoun
+// because it could not be told apart as possessive or object, e.g. "her" in
+// "helped her finish". The owner has to reword it by hand.
+type PronounReview struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+}
+
+type AnonymizedResume struct {
+	Resume        Resume          `json:"resume"`
+	Substitutions []Substitution  `json:"substitutions"`
+	Review        []PronounReview `json:"review"`
+}
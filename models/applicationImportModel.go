@@ -0,0 +1,96 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ImportField is an application field a spreadsheet column can be mapped to.
+type ImportField string
+
+const (
+	ImportCompanyName    ImportField = "company_name"
+	ImportRoleTitle      ImportField = "role_title"
+	ImportStatus         ImportField = "status"
+	ImportAppliedAt      ImportField = "applied_at"
+	ImportSource         ImportField = "source"
+	ImportLocation       ImportField = "location"
+	ImportJobURL         ImportField = "job_url"
+	ImportSalaryMin      ImportField = "salary_min"
+	ImportSalaryMax      ImportField = "salary_max"
+	ImportSalaryCurrency ImportField = "salary_currency"
+	ImportSalaryPeriod   ImportField = "salary_period"
+	ImportOfferDeadline  ImportField = "offer_deadline"
+	// ImportNotes becomes the first note of the application.
+	ImportNotes ImportField = "notes"
+)
+
+var ImportFields = []ImportField{
+	ImportCompanyName, ImportRoleTitle, ImportStatus, ImportAppliedAt, ImportSource,
+	ImportLocation, ImportJobURL, ImportSalaryMin, ImportSalaryMax, ImportSalaryCurrency,
+	ImportSalaryPeriod, ImportOfferDeadline, ImportNotes,
+}
+
+// ApplicationCSVColumns is the header of the application export. Columns
+// are only ever appended, so scripts reading the file keep working. The
+// names match ImportFields so an export can be imported again as is.
+var ApplicationCSVColumns = []string{
+	"id", "company_name", "role_title", "status", "applied_at", "source", "location",
+	"job_url", "salary_min", "salary_max", "salary_currency", "salary_period",
+	"offer_deadline", "created_at", "updated_at",
+}
+
+// ImportMapping maps application fields to CSV column headers.
+type ImportMapping map[ImportField]string
+
+// ImportOptions is sent as JSON in the "options" form field next to the
+// uploaded file.
+type ImportOptions struct {
+	// Mapping defaults to the mapping suggested from the headers.
+	Mapping ImportMapping `json:"mapping"`
+	// DayFirst reads 03/04/2024 as 3 April. When unset it is guessed from
+	// the dates in the file, falling back to month first.
+	DayFirst *bool `json:"day_first"`
+	// ImportDuplicates also imports rows that match an existing
+	// application or an earlier row.
+	ImportDuplicates bool `json:"import_duplicates"`
+}
+
+type ImportRowError struct {
+	Row     int         `json:"row"`
+	Field   ImportField `json:"field,omitempty"`
+	Column  string      `json:"column,omitempty"`
+	Message string      `json:"message"`
+}
+
+// ImportRow is one parsed row. Row is the line number in the file, with
+// the header on line 1.
+type ImportRow struct {
+	Row         int                 `json:"row"`
+	Application JobApplication      `json:"application"`
+	Note        string              `json:"note,omitempty"`
+	Errors      []ImportRowError    `json:"errors"`
+	DuplicateOf *primitive.ObjectID `json:"duplicate_of,omitempty"`
+	// DuplicateOfRow is set when an earlier row of the same file has the
+	// same company and role.
+	DuplicateOfRow int `json:"duplicate_of_row,omitempty"`
+}
+
+type ImportPreview struct {
+	Headers []string      `json:"headers"`
+	Mapping ImportMapping `json:"mapping"`
+	// Unmapped lists the headers no field is mapped to.
+	Unmapped      []string    `json:"unmapped"`
+	DayFirst      bool        `json:"day_first"`
+	TotalRows     int         `json:"total_rows"`
+	ValidRows     int         `json:"valid_rows"`
+	ErrorRows     int         `json:"error_rows"`
+	DuplicateRows int         `json:"duplicate_rows"`
+	Rows          []ImportRow `json:"rows"`
+}
+
+type ImportReport struct {
+	TotalRows         int                  `json:"total_rows"`
+	Imported          int                  `json:"imported"`
+	SkippedDuplicates int                  `json:"skipped_duplicates"`
+	Failed            int                  `json:"failed"`
+	ImportedIDs       []primitive.ObjectID `json:"imported_ids"`
+	Errors            []ImportRowError     `json:"errors"`
+}
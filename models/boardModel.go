@@ -0,0 +1,23 @@
+package models
+
+type BoardColumn struct {
+	Status ApplicationStatus `json:"status"`
+	// Count is the number of applications in the column, which can be more
+	// than the applications returned when the column is truncated.
+	Count        int              `json:"count"`
+	Applications []JobApplication `json:"applications"`
+}
+
+type Board struct {
+	TotalCount int           `json:"total_count"`
+	Columns    []BoardColumn `json:"columns"`
+}
+
+// BoardMoveRequest drops an application into a column at the given index,
+// counted without the application itself. An index past the end of the
+// column places it last.
+type BoardMoveRequest struct {
+	Status ApplicationStatus `json:"status" validate:"required,oneof=saved applied screening interviewing offer accepted rejected withdrawn ghosted"`
+	Index  int               `json:"index" validate:"gte=0"`
+	Note   string            `json:"note" validate:"max=2000"`
+}
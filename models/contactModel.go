@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Contact is a person in the user's network: a recruiter, a hiring manager
+// or someone who can refer them. A contact can be linked to any number of
+// job applications.
+type Contact struct {
+	ID              primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	UserID          primitive.ObjectID   `bson:"user_id" json:"user_id"`
+	Name            string               `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	Company         string               `bson:"company,omitempty" json:"company,omitempty" validate:"max=200"`
+	Role            string               `bson:"role,omitempty" json:"role,omitempty" validate:"max=200"`
+	Email           string               `bson:"email,omitempty" json:"email,omitempty" validate:"omitempty,email"`
+	LinkedInURL     string               `bson:"linkedin_url,omitempty" json:"linkedin_url,omitempty" validate:"omitempty,url"`
+	Notes           string               `bson:"notes,omitempty" json:"notes,omitempty" validate:"max=5000"`
+	ApplicationIDs  []primitive.ObjectID `bson:"application_ids" json:"application_ids"`
+	Tags            []string             `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
+	LastContactedAt *time.Time           `bson:"last_contacted_at,omitempty" json:"last_contacted_at,omitempty"`
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type InteractionKind string
+
+const (
+	EmailInteraction      InteractionKind = "email"
+	CallInteraction       InteractionKind = "call"
+	CoffeeChatInteraction InteractionKind = "coffee_chat"
+	MeetingInteraction    InteractionKind = "meeting"
+	MessageInteraction    InteractionKind = "message"
+	OtherInteraction      InteractionKind = "other"
+)
+
+// Interaction is one entry of the log of a user's contact with someone.
+// ApplicationID ties it to the application it was about, if any.
+type Interaction struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	ContactID     primitive.ObjectID  `bson:"contact_id" json:"contact_id"`
+	ApplicationID *primitive.ObjectID `bson:"application_id,omitempty" json:"application_id,omitempty"`
+	Kind          InteractionKind     `bson:"kind" json:"kind" validate:"required,oneof=email call coffee_chat meeting message other"`
+	Summary       string              `bson:"summary" json:"summary" validate:"required,min=1,max=5000"`
+	OccurredAt    time.Time           `bson:"occurred_at" json:"occurred_at"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CoverLetter struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	ResumeID  *primitive.ObjectID `bson:"resume_id,omitempty" json:"resume_id,omitempty"`
+	TargetJob *TargetJob          `bson:"target_job,omitempty" json:"target_job,omitempty"`
+	Title     string              `bson:"title" json:"title" validate:"required,min=1,max=200"`
+	Template  string              `bson:"template,omitempty" json:"template,omitempty"`
+	Body      string              `bson:"body" json:"body" validate:"required,max=20000"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// TargetJob is the job a cover letter is written for.
+type TargetJob struct {
+	CompanyName    string `bson:"company_name" json:"company_name" validate:"max=200"`
+	RoleTitle      string `bson:"role_title" json:"role_title" validate:"max=200"`
+	JobURL         string `bson:"job_url,omitempty" json:"job_url,omitempty" validate:"omitempty,url"`
+	JobDescription string `bson:"job_description,omitempty" json:"job_description,omitempty" validate:"max=50000"`
+}
+
+// GenerateCoverLetterRequest asks for a cover letter built from a template.
+// Template names a built-in template; TemplateText, when set, is used instead.
+type GenerateCoverLetterRequest struct {
+	Title        string              `json:"title" validate:"max=200"`
+	Template     string              `json:"template"`
+	TemplateText string              `json:"template_text" validate:"max=20000"`
+	ResumeID     *primitive.ObjectID `json:"resume_id"`
+	TargetJob    *TargetJob          `json:"target_job"`
+}
+
+// CoverLetterMergeFields are the values available to cover letter templates,
+// e.g. {{.FullName}} or {{.TargetCompany}}.
+type CoverLetterMergeFields struct {
+	FirstName      string
+	LastName       string
+	FullName       string
+	Email          string
+	CurrentCompany string
+	TopRole        string
+	TopCompany     string
+	TopBullets     []string
+	Skills         []string
+	MatchingSkills []string
+	TargetCompany  string
+	TargetRole     string
+	Date           string
+}
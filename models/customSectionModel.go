@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomSectionType string
+
+const (
+	BulletsSection  CustomSectionType = "bullets"
+	DatedSection    CustomSectionType = "dated"
+	KeyValueSection CustomSectionType = "key_value"
+)
+
+// CustomSection is a user defined resume section such as Publications,
+// Volunteering or Talks. All entries of a section share its type.
+type CustomSection struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	Title    string             `bson:"title" json:"title" validate:"required,min=1,max=100"`
+	Type     CustomSectionType  `bson:"type" json:"type" validate:"required,oneof=bullets dated key_value"`
+	Position int                `bson:"position" json:"position"`
+	Entries  []CustomEntry      `bson:"entries" json:"entries" validate:"dive"`
+}
+
+// CustomEntry holds one entry of a custom section. Which fields are used
+// depends on the section type: bullets use Text, dated entries use Title,
+// Subtitle, dates and Description, key-value entries use Key and Value.
+type CustomEntry struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Text        string             `bson:"text,omitempty" json:"text,omitempty" validate:"max=1000"`
+	Title       string             `bson:"title,omitempty" json:"title,omitempty" validate:"max=200"`
+	Subtitle    string             `bson:"subtitle,omitempty" json:"subtitle,omitempty" validate:"max=200"`
+	StartDate   time.Time          `bson:"start_date,omitempty" json:"start_date,omitempty"`
+	EndDate     time.Time          `bson:"end_date,omitempty" json:"end_date,omitempty"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty" validate:"max=2000"`
+	Key         string             `bson:"key,omitempty" json:"key,omitempty" validate:"max=100"`
+	Value       string             `bson:"value,omitempty" json:"value,omitempty" validate:"max=500"`
+}
+
+type CustomSectionOrder struct {
+	SectionIDs []primitive.ObjectID `json:"section_ids" validate:"required"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Document is an uploaded file kept in blob storage, such as the exact PDF
+// that was sent to a company. The bytes live under StorageKey; this record
+// only holds the metadata. Documents migrated from the old resume_urls list
+// have a URL instead and no stored file.
+type Document struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	ResumeID      *primitive.ObjectID `bson:"resume_id,omitempty" json:"resume_id,omitempty"`
+	ApplicationID *primitive.ObjectID `bson:"application_id,omitempty" json:"application_id,omitempty"`
+	FileName      string              `bson:"file_name" json:"file_name"`
+	ContentType   string              `bson:"content_type" json:"content_type"`
+	Size          int64               `bson:"size" json:"size"`
+	SHA256        string              `bson:"sha256" json:"sha256"`
+	StorageKey    string              `bson:"storage_key,omitempty" json:"-"`
+	URL           string              `bson:"url,omitempty" json:"url,omitempty"`
+	Description   string              `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmailKind string
+
+const (
+	ConfirmationEmail EmailKind = "application_confirmation"
+	RejectionEmail    EmailKind = "rejection"
+	InterviewEmail    EmailKind = "interview_invite"
+	OfferEmail        EmailKind = "offer"
+	UnknownEmail      EmailKind = "unknown"
+)
+
+// EmailMessage is the part of an uploaded email the classifier and the
+// matcher look at.
+type EmailMessage struct {
+	MessageID string    `json:"message_id"`
+	From      string    `json:"from"`
+	FromName  string    `json:"from_name"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
+	Text      string    `json:"-"`
+}
+
+type ProposalStatus string
+
+const (
+	ProposalPending   ProposalStatus = "pending"
+	ProposalConfirmed ProposalStatus = "confirmed"
+	ProposalDismissed ProposalStatus = "dismissed"
+)
+
+// EmailProposal is a status change suggested by an uploaded email. Nothing
+// changes on the application until the user confirms it.
+type EmailProposal struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	MessageID  string             `bson:"message_id" json:"message_id"`
+	From       string             `bson:"from" json:"from"`
+	FromName   string             `bson:"from_name,omitempty" json:"from_name,omitempty"`
+	Subject    string             `bson:"subject" json:"subject"`
+	ReceivedAt time.Time          `bson:"received_at" json:"received_at"`
+	Kind       EmailKind          `bson:"kind" json:"kind"`
+	// Cues are the phrases the kind was read from.
+	Cues []string `bson:"cues" json:"cues"`
+	// ApplicationID is nil when no application matched clearly. Candidates
+	// then lists the applications that matched equally well, if any.
+	ApplicationID  *primitive.ObjectID  `bson:"application_id,omitempty" json:"application_id,omitempty"`
+	Candidates     []primitive.ObjectID `bson:"candidates,omitempty" json:"candidates,omitempty"`
+	MatchReasons   []string             `bson:"match_reasons" json:"match_reasons"`
+	ProposedStatus ApplicationStatus    `bson:"proposed_status" json:"proposed_status"`
+	Status         ProposalStatus       `bson:"status" json:"status"`
+	ResolvedAt     *time.Time           `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+}
+
+type EmailImportReport struct {
+	Messages int `json:"messages"`
+	// Unclassified messages are not about an application's progress.
+	Unclassified int `json:"unclassified"`
+	// UpToDate messages matched an application already at or past the
+	// status they suggest.
+	UpToDate  int             `json:"up_to_date"`
+	Duplicate int             `json:"duplicate"`
+	Unmatched int             `json:"unmatched"`
+	Errors    []string        `json:"errors"`
+	Proposals []EmailProposal `json:"proposals"`
+}
+
+type ConfirmProposalRequest struct {
+	// ApplicationID picks the application for an unmatched or ambiguous
+	// proposal, or overrides the matched one.
+	ApplicationID *primitive.ObjectID `json:"application_id"`
+	Note          string              `json:"note" validate:"max=2000"`
+}
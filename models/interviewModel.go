@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InterviewFormat string
+
+const (
+	PhoneInterview    InterviewFormat = "phone"
+	VideoInterview    InterviewFormat = "video"
+	OnsiteInterview   InterviewFormat = "onsite"
+	TakeHomeInterview InterviewFormat = "take_home"
+)
+
+// Interview is one round of an application's interview loop. StartsAt is
+// kept in UTC; TimeZone is the IANA zone the interview takes place in and
+// is used when the time is shown to the user.
+type Interview struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	Round           string             `bson:"round" json:"round" validate:"required,min=1,max=100"`
+	StartsAt        time.Time          `bson:"starts_at" json:"starts_at" validate:"required"`
+	TimeZone        string             `bson:"time_zone" json:"time_zone" validate:"required,max=64"`
+	DurationMinutes int                `bson:"duration_minutes" json:"duration_minutes" validate:"required,min=5,max=1440"`
+	Format          InterviewFormat    `bson:"format" json:"format" validate:"required,oneof=phone video onsite take_home"`
+	// Location is an address for onsite rounds or a meeting link.
+	Location     string        `bson:"location,omitempty" json:"location,omitempty" validate:"max=500"`
+	Interviewers []Interviewer `bson:"interviewers" json:"interviewers" validate:"max=20,dive"`
+	PrepNote     string        `bson:"prep_note,omitempty" json:"prep_note,omitempty" validate:"max=5000"`
+	// Sequence counts the changes to the round so calendar apps replace
+	// the event they already have instead of keeping a stale copy.
+	Sequence  int       `bson:"sequence" json:"sequence"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+type Interviewer struct {
+	Name  string `bson:"name" json:"name" validate:"required,max=100"`
+	Title string `bson:"title,omitempty" json:"title,omitempty" validate:"max=100"`
+	Email string `bson:"email,omitempty" json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// CalendarFeed is the secret token behind a user's subscribable interview
+// calendar. A user has at most one; rotating it invalidates the old URL.
+type CalendarFeed struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Token     string             `bson:"token" json:"token"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
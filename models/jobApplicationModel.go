@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApplicationStatus string
+
+const (
+	StatusSaved        ApplicationStatus = "saved"
+	StatusApplied      ApplicationStatus = "applied"
+	StatusScreening    ApplicationStatus = "screening"
+	StatusInterviewing ApplicationStatus = "interviewing"
+	StatusOffer        ApplicationStatus = "offer"
+	StatusAccepted     ApplicationStatus = "accepted"
+	StatusRejected     ApplicationStatus = "rejected"
+	StatusWithdrawn    ApplicationStatus = "withdrawn"
+	StatusGhosted      ApplicationStatus = "ghosted"
+)
+
+// ApplicationStatuses lists every status in pipeline order. It is also the
+// column order of the board.
+var ApplicationStatuses = []ApplicationStatus{
+	StatusSaved, StatusApplied, StatusScreening, StatusInterviewing, StatusOffer,
+	StatusAccepted, StatusRejected, StatusWithdrawn, StatusGhosted,
+}
+
+// InitialStatuses are the statuses a new application may start in. Later
+// statuses are reached through transitions, so they show up in the history.
+var InitialStatuses = []ApplicationStatus{StatusSaved, StatusApplied}
+
+// ApplicationTransitions lists the statuses an application may move to from
+// each status. Accepted, rejected and withdrawn are final; a ghosted
+// application can still come back to life when the company replies.
+var ApplicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	StatusSaved:        {StatusApplied, StatusWithdrawn},
+	StatusApplied:      {StatusScreening, StatusInterviewing, StatusOffer, StatusRejected, StatusWithdrawn, StatusGhosted},
+	StatusScreening:    {StatusInterviewing, StatusOffer, StatusRejected, StatusWithdrawn, StatusGhosted},
+	StatusInterviewing: {StatusOffer, StatusRejected, StatusWithdrawn, StatusGhosted},
+	StatusOffer:        {StatusAccepted, StatusRejected, StatusWithdrawn},
+	StatusAccepted:     {},
+	StatusRejected:     {},
+	StatusWithdrawn:    {},
+	StatusGhosted:      {StatusScreening, StatusInterviewing, StatusOffer, StatusRejected, StatusWithdrawn},
+}
+
+type JobApplication struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	CompanyName string              `bson:"company_name" json:"company_name" validate:"required,min=1,max=200"`
+	RoleTitle   string              `bson:"role_title" json:"role_title" validate:"required,min=1,max=200"`
+	JobURL      string              `bson:"job_url,omitempty" json:"job_url,omitempty" validate:"omitempty,url"`
+	Location    string              `bson:"location,omitempty" json:"location,omitempty" validate:"max=200"`
+	Salary      *SalaryRange        `bson:"salary,omitempty" json:"salary,omitempty"`
+	Source      string              `bson:"source,omitempty" json:"source,omitempty" validate:"max=100"`
+	Tags        []string            `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
+	Status      ApplicationStatus   `bson:"status" json:"status" validate:"required,oneof=saved applied screening interviewing offer accepted rejected withdrawn ghosted"`
+	AppliedAt   *time.Time          `bson:"applied_at,omitempty" json:"applied_at,omitempty"`
+	ResumeID    *primitive.ObjectID `bson:"resume_id,omitempty" json:"resume_id,omitempty"`
+	DocumentID  *primitive.ObjectID `bson:"document_id,omitempty" json:"document_id,omitempty"`
+	// ResumeSnapshot is a copy of the resume taken when it was linked, so
+	// later edits to the resume do not change what was sent.
+	ResumeSnapshot *Resume        `bson:"resume_snapshot,omitempty" json:"resume_snapshot,omitempty"`
+	OfferDeadline  *time.Time     `bson:"offer_deadline,omitempty" json:"offer_deadline,omitempty"`
+	Interviews     []Interview    `bson:"interviews,omitempty" json:"interviews,omitempty"`
+	Offer          *Offer         `bson:"offer,omitempty" json:"offer,omitempty"`
+	StatusHistory  []StatusChange `bson:"status_history" json:"status_history"`
+	// BoardPosition orders the application within its board column. Moving
+	// a card picks a value between its new neighbours, so only the moved
+	// application is written.
+	BoardPosition float64   `bson:"board_position" json:"board_position"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// StatusChange is one entry of an application's status history. From is
+// empty for the status the application was created with.
+type StatusChange struct {
+	From ApplicationStatus `bson:"from,omitempty" json:"from,omitempty"`
+	To   ApplicationStatus `bson:"to" json:"to"`
+	At   time.Time         `bson:"at" json:"at"`
+	Note string            `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+type StatusTransitionRequest struct {
+	Status ApplicationStatus `json:"status" validate:"required,oneof=saved applied screening interviewing offer accepted rejected withdrawn ghosted"`
+	Note   string            `json:"note" validate:"max=2000"`
+	// At backdates the transition, e.g. when logging a rejection that
+	// arrived last week. It defaults to now.
+	At *time.Time `json:"at"`
+}
+
+type SalaryRange struct {
+	Min      float64 `bson:"min" json:"min" validate:"gte=0"`
+	Max      float64 `bson:"max" json:"max" validate:"gte=0,gtefield=Min"`
+	Currency string  `bson:"currency" json:"currency" validate:"required,len=3"`
+	Period   string  `bson:"period" json:"period" validate:"required,oneof=year month hour"`
+}
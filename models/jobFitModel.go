@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FitFactor string
+
+const (
+	SkillsFit    FitFactor = "skills"
+	SeniorityFit FitFactor = "seniority"
+	LocationFit  FitFactor = "location"
+)
+
+// FitComponent is one factor of a fit score. Score is between 0 and 1 and
+// contributes Score*Weight points to the total.
+type FitComponent struct {
+	Factor      FitFactor `bson:"factor" json:"factor"`
+	Score       float64   `bson:"score" json:"score"`
+	Weight      int       `bson:"weight" json:"weight"`
+	Explanation string    `bson:"explanation" json:"explanation"`
+}
+
+// JobFit scores a saved job against a resume. The stamps record the inputs
+// the score was computed from, so a stored fit is only reused while the
+// job, the resume and the user's experience level are unchanged.
+type JobFit struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID          primitive.ObjectID `bson:"user_id" json:"-"`
+	JobID           primitive.ObjectID `bson:"job_id" json:"job_id"`
+	ResumeID        primitive.ObjectID `bson:"resume_id" json:"resume_id"`
+	Score           int                `bson:"score" json:"score"`
+	Components      []FitComponent     `bson:"components" json:"components"`
+	MatchedSkills   []string           `bson:"matched_skills" json:"matched_skills"`
+	MissingSkills   []string           `bson:"missing_skills" json:"missing_skills"`
+	JobUpdatedAt    time.Time          `bson:"job_updated_at" json:"-"`
+	ResumeUpdatedAt time.Time          `bson:"resume_updated_at" json:"-"`
+	ExperienceLevel ExperienceLevel    `bson:"experience_level" json:"-"`
+	ComputedAt      time.Time          `bson:"computed_at" json:"computed_at"`
+}
+
+type RankedJob struct {
+	Rank int      `json:"rank"`
+	Job  SavedJob `json:"job"`
+	Fit  JobFit   `json:"fit"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// ResumeLayout controls how a resume is rendered without changing its
+// content. Sections are referred to by their JSON key ("education",
+// "work_experience", ...) or, for custom sections, by their hex ID.
+// Sections missing from SectionOrder follow in their default order.
+type ResumeLayout struct {
+	SectionOrder   []string `bson:"section_order,omitempty" json:"section_order,omitempty"`
+	HiddenSections []string `bson:"hidden_sections,omitempty" json:"hidden_sections,omitempty"`
+	// HiddenEntries maps a section to the entries to leave out, by entry
+	// ID, e.g. {"work_experience": ["65f1..."]}. Skills and languages have
+	// no IDs and are named by their text, e.g. {"skills": ["COBOL"]}.
+	HiddenEntries map[string][]string `bson:"hidden_entries,omitempty" json:"hidden_entries,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// SupportedLocales lists the languages resumes can be rendered in.
+var SupportedLocales = []string{"en", "de", "fr", "es"}
+
+const DefaultLocale = "en"
+
+// LocaleSyncIssue flags an entry that exists in one translation of a resume
+// but has no counterpart in another.
+type LocaleSyncIssue struct {
+	Section   string `json:"section"`
+	Entry     string `json:"entry"`
+	PresentIn string `json:"present_in"`
+	MissingIn string `json:"missing_in"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type CreateLocaleRequest struct {
+	Locale string `json:"locale" validate:"required,oneof=en de fr es"`
+}
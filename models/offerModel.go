@@ -0,0 +1,95 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type EquityType string
+
+const (
+	RSUEquity    EquityType = "rsu"
+	OptionEquity EquityType = "options"
+)
+
+// Offer is the compensation package of an application that reached the
+// offer stage. Amounts are yearly and in Currency unless noted.
+type Offer struct {
+	Currency   string  `bson:"currency" json:"currency" validate:"required,len=3"`
+	BaseSalary float64 `bson:"base_salary" json:"base_salary" validate:"required,gt=0"`
+	// BonusPercent is the target bonus as a share of base salary, and
+	// BonusAmount a fixed yearly bonus. Both may be set.
+	BonusPercent float64        `bson:"bonus_percent,omitempty" json:"bonus_percent,omitempty" validate:"gte=0,lte=200"`
+	BonusAmount  float64        `bson:"bonus_amount,omitempty" json:"bonus_amount,omitempty" validate:"gte=0"`
+	SignOnBonus  float64        `bson:"sign_on_bonus,omitempty" json:"sign_on_bonus,omitempty" validate:"gte=0"`
+	Equity       []EquityGrant  `bson:"equity,omitempty" json:"equity,omitempty" validate:"max=20,dive"`
+	Benefits     []OfferBenefit `bson:"benefits,omitempty" json:"benefits,omitempty" validate:"max=50,dive"`
+	PTODays      int            `bson:"pto_days,omitempty" json:"pto_days,omitempty" validate:"gte=0,lte=365"`
+	Location     string         `bson:"location,omitempty" json:"location,omitempty" validate:"max=200"`
+	Remote       bool           `bson:"remote" json:"remote"`
+	// HoursPerWeek is used for per-hour figures. It defaults to 40.
+	HoursPerWeek float64 `bson:"hours_per_week,omitempty" json:"hours_per_week,omitempty" validate:"gte=0,lte=100"`
+}
+
+// EquityGrant is one grant of stock or options. It is valued either from
+// Shares and PricePerShare or, when there is no share count, from
+// GrantValue. Options are valued at the spread over StrikePrice.
+type EquityGrant struct {
+	Type          EquityType `bson:"type" json:"type" validate:"required,oneof=rsu options"`
+	Shares        float64    `bson:"shares,omitempty" json:"shares,omitempty" validate:"gte=0"`
+	PricePerShare float64    `bson:"price_per_share,omitempty" json:"price_per_share,omitempty" validate:"gte=0"`
+	StrikePrice   float64    `bson:"strike_price,omitempty" json:"strike_price,omitempty" validate:"gte=0"`
+	GrantValue    float64    `bson:"grant_value,omitempty" json:"grant_value,omitempty" validate:"gte=0"`
+	// Currency defaults to the offer's, for grants priced in another
+	// currency than the salary.
+	Currency     string `bson:"currency,omitempty" json:"currency,omitempty" validate:"omitempty,len=3"`
+	VestingYears int    `bson:"vesting_years" json:"vesting_years" validate:"required,min=1,max=10"`
+	CliffMonths  int    `bson:"cliff_months,omitempty" json:"cliff_months,omitempty" validate:"gte=0,lte=60"`
+	// YearlyPercent replaces even vesting with a back-loaded schedule
+	// such as 5, 15, 40, 40. It must have VestingYears entries summing to
+	// 100; the cliff is ignored with it.
+	YearlyPercent []float64 `bson:"yearly_percent,omitempty" json:"yearly_percent,omitempty" validate:"omitempty,dive,gte=0,lte=100"`
+}
+
+type OfferBenefit struct {
+	Name        string  `bson:"name" json:"name" validate:"required,max=100"`
+	AnnualValue float64 `bson:"annual_value" json:"annual_value" validate:"gte=0"`
+}
+
+// OfferComparisonRequest compares the offers of the given applications, or
+// of every application with an offer when none are given. Rates maps a
+// currency code to the value of one unit of it in Currency.
+type OfferComparisonRequest struct {
+	Currency       string               `json:"currency" validate:"required,len=3"`
+	Rates          map[string]float64   `json:"rates" validate:"dive,keys,len=3,endkeys,gt=0"`
+	ApplicationIDs []primitive.ObjectID `json:"application_ids" validate:"max=20"`
+}
+
+// CompensationBreakdown is the compensation of one period by component.
+type CompensationBreakdown struct {
+	Base     float64 `json:"base"`
+	Bonus    float64 `json:"bonus"`
+	SignOn   float64 `json:"sign_on"`
+	Equity   float64 `json:"equity"`
+	Benefits float64 `json:"benefits"`
+	Total    float64 `json:"total"`
+}
+
+type OfferSummary struct {
+	ApplicationID primitive.ObjectID    `json:"application_id"`
+	CompanyName   string                `json:"company_name"`
+	RoleTitle     string                `json:"role_title"`
+	Location      string                `json:"location,omitempty"`
+	Remote        bool                  `json:"remote"`
+	Year1         CompensationBreakdown `json:"year_1"`
+	// FourYearAverage spreads the sign-on bonus and the equity vesting
+	// over four years, which is where back-loaded grants catch up.
+	FourYearAverage CompensationBreakdown `json:"four_year_average"`
+	// YearlyTotals are the totals of years one to four.
+	YearlyTotals           []float64 `json:"yearly_totals"`
+	Year1PerHour           float64   `json:"year_1_per_hour"`
+	FourYearAveragePerHour float64   `json:"four_year_average_per_hour"`
+	Notes                  []string  `json:"notes"`
+}
+
+type OfferComparison struct {
+	Currency string         `json:"currency"`
+	Offers   []OfferSummary `json:"offers"`
+}
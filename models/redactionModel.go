@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RedactionProfile string
+type LocationGranularity string
+
+const (
+	PublicWebProfile RedactionProfile = "public_web"
+	RecruiterProfile RedactionProfile = "recruiter"
+	FullProfile      RedactionProfile = "full"
+
+	// Locations are stored as comma separated parts, most specific first,
+	// e.g. "Indiranagar, Bengaluru, Karnataka, India".
+	LocationFull    LocationGranularity = "full"
+	LocationCity    LocationGranularity = "city"
+	LocationCountry LocationGranularity = "country"
+	LocationHidden  LocationGranularity = "hidden"
+)
+
+// RedactionRules lists the fields a profile holds back when a resume leaves
+// the owner's hands.
+type RedactionRules struct {
+	HidePhoneNumber bool                `json:"hide_phone_number"`
+	HideEmail       bool                `json:"hide_email"`
+	HideGPA         bool                `json:"hide_gpa"`
+	Location        LocationGranularity `json:"location"`
+}
+
+var RedactionProfiles = map[RedactionProfile]RedactionRules{
+	PublicWebProfile: {
+		HidePhoneNumber: true,
+		HideEmail:       true,
+		HideGPA:         true,
+		Location:        LocationCountry,
+	},
+	RecruiterProfile: {
+		HidePhoneNumber: false,
+		HideEmail:       false,
+		HideGPA:         false,
+		Location:        LocationCity,
+	},
+	FullProfile: {
+		Location: LocationFull,
+	},
+}
+
+// ResumeShare is a public, token addressed view of a resume. The profile is
+// fixed when the link is created so a viewer cannot ask for more.
+type ResumeShare struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ResumeID  primitive.ObjectID `bson:"resume_id" json:"resume_id"`
+	Token     string             `bson:"token" json:"token"`
+	Profile   RedactionProfile   `bson:"profile" json:"profile" validate:"required,oneof=public_web recruiter full"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ResumeExport is what a JSON export hands out: the contact details left
+// by the redaction profile and the visible sections in render order. It
+// carries no ids, tags or layout settings of the stored resume.
+type ResumeExport struct {
+	Name          string          `json:"name"`
+	Email         string          `json:"email,omitempty"`
+	PhoneNumber   string          `json:"phone_number,omitempty"`
+	Location      string          `json:"location,omitempty"`
+	LinkedInLink  *string         `json:"linkedin_link,omitempty"`
+	GitHubLink    *string         `json:"github_link,omitempty"`
+	PortfolioLink *string         `json:"portfolio_link,omitempty"`
+	Locale        string          `json:"locale,omitempty"`
+	Sections      []ExportSection `json:"sections"`
+}
+
+// ExportSection is one rendered section. Key is the section's JSON key, or
+// "custom" for a user defined section, whose Type is then set. Content has
+// the shape of the matching Resume field.
+type ExportSection struct {
+	Key     string            `json:"key"`
+	Heading string            `json:"heading"`
+	Type    CustomSectionType `json:"type,omitempty"`
+	Content interface{}       `json:"content"`
+}
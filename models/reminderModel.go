@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReminderKind string
+
+const (
+	ReminderNoResponse    ReminderKind = "no_response"
+	ReminderThankYou      ReminderKind = "thank_you"
+	ReminderOfferDeadline ReminderKind = "offer_deadline"
+)
+
+type Reminder struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ApplicationID primitive.ObjectID `bson:"application_id" json:"application_id"`
+	Kind          ReminderKind       `bson:"kind" json:"kind"`
+	// Key identifies the event a reminder is for, e.g. the application
+	// being applied to at a given time. It is unique, so scanning the same
+	// application again never creates a second reminder.
+	Key     string    `bson:"key" json:"-"`
+	Message string    `bson:"message" json:"message"`
+	DueAt   time.Time `bson:"due_at" json:"due_at"`
+	// ClaimedUntil is set while a worker is delivering the reminder so
+	// that no other worker picks it up at the same time.
+	ClaimedUntil *time.Time `bson:"claimed_until,omitempty" json:"-"`
+	DeliveredAt  *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	DismissedAt  *time.Time `bson:"dismissed_at,omitempty" json:"dismissed_at,omitempty"`
+	Attempts     int        `bson:"attempts" json:"attempts"`
+	LastError    string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,87 @@
+package models
+
+type DegreeLevel string
+
+const (
+	NoDegree        DegreeLevel = ""
+	AssociateDegree DegreeLevel = "associate"
+	BachelorDegree  DegreeLevel = "bachelor"
+	MasterDegree    DegreeLevel = "master"
+	DoctorateDegree DegreeLevel = "doctorate"
+)
+
+// DegreeRank orders degree levels so they can be compared.
+var DegreeRank = map[DegreeLevel]int{
+	NoDegree:        0,
+	AssociateDegree: 1,
+	BachelorDegree:  2,
+	MasterDegree:    3,
+	DoctorateDegree: 4,
+}
+
+type SeniorityLevel string
+
+const (
+	EntrySeniority  SeniorityLevel = "entry"
+	MidSeniority    SeniorityLevel = "mid"
+	SeniorSeniority SeniorityLevel = "senior"
+	LeadSeniority   SeniorityLevel = "lead"
+)
+
+// JobRequirements is what a job description asks for, split into hard
+// requirements and nice-to-haves.
+type JobRequirements struct {
+	MustHaveSkills     []string    `json:"must_have_skills"`
+	NiceToHaveSkills   []string    `json:"nice_to_have_skills"`
+	MinYearsExperience int         `json:"min_years_experience,omitempty"`
+	Degree             DegreeLevel `json:"degree,omitempty"`
+	// DegreeRequired is false when the degree is only preferred or
+	// equivalent experience is accepted instead.
+	DegreeRequired bool           `json:"degree_required"`
+	Certifications []string       `json:"certifications"`
+	Seniority      SeniorityLevel `json:"seniority,omitempty"`
+	// SeniorityCues are the words the seniority was read from.
+	SeniorityCues []string `json:"seniority_cues"`
+}
+
+type RequirementStatus string
+
+const (
+	RequirementMet     RequirementStatus = "met"
+	RequirementPartial RequirementStatus = "partial"
+	RequirementMissing RequirementStatus = "missing"
+)
+
+type RequirementCategory string
+
+const (
+	SkillRequirement         RequirementCategory = "skill"
+	ExperienceRequirement    RequirementCategory = "experience"
+	DegreeRequirement        RequirementCategory = "degree"
+	CertificationRequirement RequirementCategory = "certification"
+	SeniorityRequirement     RequirementCategory = "seniority"
+)
+
+type RequirementCheck struct {
+	Category    RequirementCategory `json:"category"`
+	Requirement string              `json:"requirement"`
+	Required    bool                `json:"required"`
+	Status      RequirementStatus   `json:"status"`
+	Detail      string              `json:"detail"`
+}
+
+// RequirementReport compares a job's requirements with a resume.
+type RequirementReport struct {
+	Requirements JobRequirements    `json:"requirements"`
+	ResumeYears  float64            `json:"resume_years"`
+	ResumeDegree DegreeLevel        `json:"resume_degree,omitempty"`
+	Checklist    []RequirementCheck `json:"checklist"`
+	// MissingRequired counts the hard requirements the resume does not
+	// meet at all.
+	MissingRequired int `json:"missing_required"`
+}
+
+type RequirementCheckRequest struct {
+	Title       string `json:"title" validate:"max=200"`
+	Description string `json:"description" validate:"required,max=100000"`
+}
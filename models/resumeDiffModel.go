@@ -0,0 +1,48 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type DiffStatus string
+
+const (
+	DiffAdded     DiffStatus = "added"
+	DiffRemoved   DiffStatus = "removed"
+	DiffChanged   DiffStatus = "changed"
+	DiffUnchanged DiffStatus = "unchanged"
+)
+
+// ResumeDiff is a semantic comparison of two resumes. Entries are aligned by
+// identity (company and role, project name) rather than by position.
+type ResumeDiff struct {
+	BaseID         primitive.ObjectID `json:"base_id"`
+	TargetID       primitive.ObjectID `json:"target_id"`
+	SummaryChanged bool               `json:"summary_changed"`
+	Skills         SetDiff            `json:"skills"`
+	WorkExperience []EntryDiff        `json:"work_experience"`
+	Projects       []EntryDiff        `json:"projects"`
+}
+
+type EntryDiff struct {
+	Key          string     `json:"key"`
+	Status       DiffStatus `json:"status"`
+	Bullets      BulletDiff `json:"bullets"`
+	Technologies *SetDiff   `json:"technologies,omitempty"`
+}
+
+type BulletDiff struct {
+	Added     []string         `json:"added"`
+	Removed   []string         `json:"removed"`
+	Reworded  []RewordedBullet `json:"reworded"`
+	Unchanged int              `json:"unchanged"`
+}
+
+type RewordedBullet struct {
+	Base       string  `json:"base"`
+	Target     string  `json:"target"`
+	Similarity float64 `json:"similarity"`
+}
+
+type SetDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
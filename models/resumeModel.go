@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,6 +10,8 @@ import (
 type Resume struct {
 	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	UserID           primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Locale           string             `bson:"locale,omitempty" json:"locale,omitempty"`
+	LocaleGroupID    primitive.ObjectID `bson:"locale_group_id,omitempty" json:"locale_group_id"`
 	Name             string             `bson:"name" json:"name"`
 	Email            string             `bson:"email" json:"email"`
 	PhoneNumber      string             `bson:"phone_number" json:"phone_number"`
@@ -25,52 +28,84 @@ type Resume struct {
 	Languages        []string           `bson:"languages,omitempty" json:"languages,omitempty"`
 	HonorsAwards     []HonorAward       `bson:"honors_awards,omitempty" json:"honors_awards,omitempty"`
 	Extracurriculars []Extracurricular  `bson:"extracurriculars,omitempty" json:"extracurriculars,omitempty"`
+	CustomSections   []CustomSection    `bson:"custom_sections,omitempty" json:"custom_sections,omitempty"`
+	Layout           *ResumeLayout      `bson:"layout,omitempty" json:"layout,omitempty"`
+	Tags             []string           `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
 	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 type Education struct {
-	Name      string    `bson:"name" json:"name"`
-	Location  string    `bson:"location,omitempty" json:"location"`
-	StartDate time.Time `bson:"start_date" json:"start_date"`
-	EndDate   time.Time `bson:"end_date" json:"end_date"`
-	IsEnrolled bool      `bson:"is_enrolled,omitempty" json:"is_enrolled"`
-	ExpectedGraduationDate time.Time `bson:"expected_graduation_date,omitempty" json:"expected_graduation_date,omitempty"`
-	GPA       float64   `bson:"gpa,omitempty" json:"gpa,omitempty"`
+	ID                     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name                   string             `bson:"name" json:"name"`
+	Location               string             `bson:"location,omitempty" json:"location"`
+	StartDate              time.Time          `bson:"start_date" json:"start_date"`
+	EndDate                time.Time          `bson:"end_date" json:"end_date"`
+	IsEnrolled             bool               `bson:"is_enrolled,omitempty" json:"is_enrolled"`
+	ExpectedGraduationDate time.Time          `bson:"expected_graduation_date,omitempty" json:"expected_graduation_date,omitempty"`
+	GPA                    float64            `bson:"gpa,omitempty" json:"gpa,omitempty"`
+}
+
+// MarshalJSON leaves out dates that are not set, such as the dates removed
+// from an anonymized resume, instead of writing them as year 1.
+func (e Education) MarshalJSON() ([]byte, error) {
+	type education Education
+	optional := func(date time.Time) *time.Time {
+		if date.IsZero() {
+			return nil
+		}
+		return &date
+	}
+	return json.Marshal(struct {
+		education
+		StartDate              *time.Time `json:"start_date,omitempty"`
+		EndDate                *time.Time `json:"end_date,omitempty"`
+		ExpectedGraduationDate *time.Time `json:"expected_graduation_date,omitempty"`
+	}{
+		education:              education(e),
+		StartDate:              optional(e.StartDate),
+		EndDate:                optional(e.EndDate),
+		ExpectedGraduationDate: optional(e.ExpectedGraduationDate),
+	})
 }
 
 type WorkExperience struct {
-	CompanyName  string    `bson:"company_name" json:"company_name"`
-	RoleTitle    string    `bson:"role_title" json:"role_title"`
-	StartDate    time.Time `bson:"start_date" json:"start_date"`
-	EndDate      time.Time `bson:"end_date,omitempty" json:"end_date,omitempty"`
-	IsWorking    bool      `bson:"is_working,omitempty" json:"is_working"`
-	Location     string    `bson:"location" json:"location"`
-	BulletPoints []string  `bson:"bullet_points" json:"bullet_points"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CompanyName  string             `bson:"company_name" json:"company_name"`
+	RoleTitle    string             `bson:"role_title" json:"role_title"`
+	StartDate    time.Time          `bson:"start_date" json:"start_date"`
+	EndDate      time.Time          `bson:"end_date,omitempty" json:"end_date,omitempty"`
+	IsWorking    bool               `bson:"is_working,omitempty" json:"is_working"`
+	Location     string             `bson:"location" json:"location"`
+	BulletPoints []string           `bson:"bullet_points" json:"bullet_points"`
 }
 
 type Project struct {
-	Name         string   `bson:"name" json:"name"`
-	Description  *string  `bson:"description,omitempty" json:"description,omitempty"`
-	ProjectUrl   *string   `bson:"project_url,omitempty" json:"project_url,omitempty"`
-	Technologies []string `bson:"technologies,omitempty" json:"technologies,omitempty"`
-	BulletPoints []string `bson:"bullet_points" json:"bullet_points"`
-	StartDate    time.Time `bson:"start_date" json:"start_date"`
-	EndDate      time.Time `bson:"end_date,omitempty" json:"end_date,omitempty"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name         string             `bson:"name" json:"name"`
+	Description  *string            `bson:"description,omitempty" json:"description,omitempty"`
+	ProjectUrl   *string            `bson:"project_url,omitempty" json:"project_url,omitempty"`
+	Technologies []string           `bson:"technologies,omitempty" json:"technologies,omitempty"`
+	BulletPoints []string           `bson:"bullet_points" json:"bullet_points"`
+	StartDate    time.Time          `bson:"start_date" json:"start_date"`
+	EndDate      time.Time          `bson:"end_date,omitempty" json:"end_date,omitempty"`
 }
 
 type Certification struct {
-	Title       string `bson:"title" json:"title"`
-	Description string `bson:"description" json:"description"`
-	CertificateLink string `bson:"certificate_link,omitempty" json:"certificate_link"`
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Title           string             `bson:"title" json:"title"`
+	Description     string             `bson:"description" json:"description"`
+	CertificateLink string             `bson:"certificate_link,omitempty" json:"certificate_link"`
 }
 
 type HonorAward struct {
-	Title       string `bson:"title" json:"title"`
-	Description string `bson:"description" json:"description"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description" json:"description"`
 }
 
 type Extracurricular struct {
-	ActivityName string `bson:"activity_name" json:"activity_name"`
-	Description  string `bson:"description" json:"description"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ActivityName string             `bson:"activity_name" json:"activity_name"`
+	Description  string             `bson:"description" json:"description"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobPostingSource string
+
+const (
+	ManualJobSource    JobPostingSource = "manual"
+	JSONLDJobSource    JobPostingSource = "json_ld"
+	HeuristicJobSource JobPostingSource = "heuristic"
+)
+
+// SavedJob is a job posting the user is interested in but has not
+// necessarily applied to yet.
+type SavedJob struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Title          string             `bson:"title" json:"title" validate:"required,min=1,max=200"`
+	CompanyName    string             `bson:"company_name,omitempty" json:"company_name,omitempty" validate:"max=200"`
+	Location       string             `bson:"location,omitempty" json:"location,omitempty" validate:"max=200"`
+	Remote         bool               `bson:"remote" json:"remote"`
+	EmploymentType string             `bson:"employment_type,omitempty" json:"employment_type,omitempty" validate:"max=100"`
+	Salary         *SalaryRange       `bson:"salary,omitempty" json:"salary,omitempty"`
+	DatePosted     *time.Time         `bson:"date_posted,omitempty" json:"date_posted,omitempty"`
+	ValidThrough   *time.Time         `bson:"valid_through,omitempty" json:"valid_through,omitempty"`
+	Description    string             `bson:"description,omitempty" json:"description,omitempty" validate:"max=100000"`
+	SourceURL      string             `bson:"source_url,omitempty" json:"source_url,omitempty" validate:"omitempty,url"`
+	Tags           []string           `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
+	// Source records how the posting was captured, so the user knows how
+	// far to trust fields that were guessed.
+	Source    JobPostingSource `bson:"source" json:"source"`
+	CreatedAt time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+type IngestJobRequest struct {
+	HTML      string `json:"html" validate:"required,max=5000000"`
+	SourceURL string `json:"source_url" validate:"omitempty,url"`
+}
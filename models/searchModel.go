@@ -0,0 +1,22 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// SearchHit points at a single piece of resume text that matched a search.
+// EntryIndex and BulletIndex are -1 when the section has no entries or the
+// match is not inside a bullet point. SectionID is set for custom sections
+// and EntryID for entries that have an ID. Text is the raw text, while
+// Highlight is HTML escaped with the matches wrapped in <mark>.
+type SearchHit struct {
+	ResumeID    primitive.ObjectID `json:"resume_id"`
+	Locale      string             `json:"locale,omitempty"`
+	Section     string             `json:"section"`
+	SectionID   string             `json:"section_id,omitempty"`
+	EntryIndex  int                `json:"entry_index"`
+	EntryID     string             `json:"entry_id,omitempty"`
+	EntryTitle  string             `json:"entry_title,omitempty"`
+	BulletIndex int                `json:"bullet_index"`
+	Text        string             `json:"text"`
+	Highlight   string             `json:"highlight"`
+	Score       int                `json:"score"`
+}
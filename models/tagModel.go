@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FilterTarget names a list that can be tagged and filtered.
+type FilterTarget string
+
+const (
+	ApplicationTarget FilterTarget = "applications"
+	ResumeTarget      FilterTarget = "resumes"
+	ContactTarget     FilterTarget = "contacts"
+	SavedJobTarget    FilterTarget = "saved_jobs"
+)
+
+var FilterTargets = []FilterTarget{ApplicationTarget, ResumeTarget, ContactTarget, SavedJobTarget}
+
+// FilterParams are the query parameters of each list that a saved filter
+// may hold, besides tags.
+var FilterParams = map[FilterTarget][]string{
+	ApplicationTarget: {"status", "company", "applied_from", "applied_to"},
+	ResumeTarget:      {},
+	ContactTarget:     {"company", "application_id"},
+	SavedJobTarget:    {},
+}
+
+// SavedFilter is a named set of list filters, such as remote backend
+// applications applied to this month. Passing ?filter_id= to the list of
+// its Target applies it. Dates in Query may be relative, like this_month or
+// 30d, so the filter stays useful.
+type SavedFilter struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	Target    FilterTarget       `bson:"target" json:"target" validate:"required,oneof=applications resumes contacts saved_jobs"`
+	Tags      []string           `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
+	Query     map[string]string  `bson:"query,omitempty" json:"query,omitempty" validate:"max=10,dive,keys,min=1,max=50,endkeys,max=500"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TagsRequest replaces the tags of a resume, application, contact or saved
+// job. An empty list removes them all.
+type TagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,min=1,max=40,excludesall=0x2C"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=40,excludesall=0x2C"`
+}
+
+// TagUsage is one of the user's tags with the number of items carrying it
+// in each list.
+type TagUsage struct {
+	Name   string               `json:"name"`
+	Total  int                  `json:"total"`
+	Counts map[FilterTarget]int `json:"counts"`
+}
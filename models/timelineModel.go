@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApplicationNote is a Markdown note on a job application. Notes are never
+// deleted; editing one moves the previous text into Revisions.
+type ApplicationNote struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ApplicationID primitive.ObjectID `bson:"application_id" json:"application_id"`
+	Body          string             `bson:"body" json:"body" validate:"required,min=1,max=20000"`
+	Revisions     []NoteRevision     `bson:"revisions" json:"revisions"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// NoteRevision is an earlier text of a note and when it was replaced.
+type NoteRevision struct {
+	Body       string    `bson:"body" json:"body"`
+	ReplacedAt time.Time `bson:"replaced_at" json:"replaced_at"`
+}
+
+type TimelineEventKind string
+
+const (
+	NoteEvent         TimelineEventKind = "note"
+	StatusChangeEvent TimelineEventKind = "status_change"
+	DocumentEvent     TimelineEventKind = "document"
+	ReminderEvent     TimelineEventKind = "reminder"
+	InteractionEvent  TimelineEventKind = "interaction"
+)
+
+// TimelineEvent is one entry of an application's activity feed. Data holds
+// the record the event was built from, e.g. the note or the interaction.
+type TimelineEvent struct {
+	Kind  TimelineEventKind   `json:"kind"`
+	At    time.Time           `json:"at"`
+	RefID *primitive.ObjectID `json:"ref_id,omitempty"`
+	Title string              `json:"title"`
+	Body  string              `json:"body,omitempty"`
+	Data  interface{}         `json:"data,omitempty"`
+}
@@ -0,0 +1,85 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"crafter/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Notifier delivers a due reminder to its user.
+type Notifier interface {
+	Notify(ctx context.Context, user models.User, reminder models.Reminder) error
+}
+
+// NotifierInstance builds the notifier selected by REMINDER_NOTIFIER.
+// Reminders are written to the log unless "webhook" is requested.
+func NotifierInstance() Notifier {
+	switch os.Getenv("REMINDER_NOTIFIER") {
+	case "webhook":
+		notifier, err := NewWebhookNotifier(os.Getenv("REMINDER_WEBHOOK_URL"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return notifier
+	default:
+		return LogNotifier{}
+	}
+}
+
+// LogNotifier writes reminders to the server log. It is meant for local
+// development. Users are logged by ID so the log holds no contact details.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, user models.User, reminder models.Reminder) error {
+	log.Printf("reminder for user %s: %s", user.ID.Hex(), reminder.Message)
+	return nil
+}
+
+// WebhookNotifier posts reminders as JSON to a URL, which can hand them on
+// to email, chat or push delivery.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Email     string          `json:"email"`
+	FirstName string          `json:"first_name"`
+	Reminder  models.Reminder `json:"reminder"`
+}
+
+func NewWebhookNotifier(url string) (*WebhookNotifier, error) {
+	if url == "" {
+		return nil, errors.New("REMINDER_WEBHOOK_URL is required for the webhook notifier")
+	}
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, user models.User, reminder models.Reminder) error {
+	body, err := json.Marshal(webhookPayload{Email: user.Email, FirstName: user.FirstName, Reminder: reminder})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("reminder webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package reminders
+
+import (
+	"context"
+	"crafter/database"
+	"crafter/models"
+	"crafter/utils"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var applicationCollection *mongo.Collection = database.OpenCollection(database.Client, "job_application")
+var reminderCollection *mongo.Collection = database.OpenCollection(database.Client, "reminder")
+var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
+
+const (
+	// maxAttempts is how often delivery of a reminder is tried before it
+	// is given up on.
+	maxAttempts = 5
+	// claimLease is how long a worker may hold a reminder it is
+	// delivering. A worker that dies mid-delivery frees it after this.
+	claimLease = 5 * time.Minute
+)
+
+// Worker scans job applications for due follow-ups, stores them as
+// reminders and delivers them through its notifier.
+//
+// Reminders survive restarts because every step is recorded in the
+// database: a reminder's unique key stops a rescan from creating it twice,
+// and delivered_at stops it from being sent twice. The only window left is
+// a crash between the notifier succeeding and delivered_at being written,
+// in which case the reminder is sent again once its claim runs out.
+type Worker struct {
+	Notifier Notifier
+	Interval time.Duration
+}
+
+// NewWorker builds a worker that runs every REMINDER_INTERVAL, 15 minutes
+// by default.
+func NewWorker(notifier Notifier) *Worker {
+	interval, err := time.ParseDuration(os.Getenv("REMINDER_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	return &Worker{Notifier: notifier, Interval: interval}
+}
+
+// Run scans immediately and then on every tick until ctx is cancelled.
+// Nothing is scheduled until the indexes exist, since the unique key index
+// is what stops a reminder from being created twice; creating them is
+// retried on every tick.
+func (w *Worker) Run(ctx context.Context) {
+	indexed := false
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if !indexed {
+			if err := ensureIndexes(ctx); err != nil {
+				log.Printf("reminders: creating indexes failed, not scheduling until they exist: %v", err)
+			} else {
+				indexed = true
+			}
+		}
+		if indexed {
+			w.RunOnce(ctx, time.Now())
+		} else if err := w.deliver(ctx, time.Now()); err != nil {
+			log.Printf("reminders: delivery failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce creates the reminders due at now and delivers every pending one.
+func (w *Worker) RunOnce(ctx context.Context, now time.Time) {
+	if err := schedule(ctx, now); err != nil {
+		log.Printf("reminders: scheduling failed: %v", err)
+	}
+	if err := w.deliver(ctx, now); err != nil {
+		log.Printf("reminders: delivery failed: %v", err)
+	}
+}
+
+func ensureIndexes(ctx context.Context) error {
+	_, err := reminderCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"key", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"delivered_at", 1}, {"due_at", 1}}},
+		{Keys: bson.D{{"user_id", 1}, {"due_at", -1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = applicationCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"status", 1}, {"applied_at", 1}}},
+		{Keys: bson.D{{"interviews.starts_at", 1}}},
+		{Keys: bson.D{{"status", 1}, {"offer_deadline", 1}}},
+	})
+	return err
+}
+
+// schedule stores a reminder for every application with a follow-up due.
+// Each branch of the query only reaches back as far as DueReminders does,
+// so old applications are not loaded on every scan.
+func schedule(ctx context.Context, now time.Time) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": models.StatusApplied, "applied_at": bson.M{
+			"$gte": now.Add(-utils.NoResponseAfter - utils.FollowUpWindow),
+			"$lte": now.Add(-utils.NoResponseAfter),
+		}},
+		bson.M{"status": models.StatusInterviewing, "interviews.0": bson.M{"$exists": false}, "status_history": bson.M{"$elemMatch": bson.M{
+			"to": models.StatusInterviewing,
+			"at": bson.M{"$gte": now.Add(-utils.ThankYouAfter - utils.FollowUpWindow), "$lte": now.Add(-utils.ThankYouAfter)},
+		}}},
+		bson.M{"interviews.starts_at": bson.M{"$gte": now.Add(-utils.ThankYouWindow - 24*time.Hour), "$lte": now}},
+		bson.M{"status": models.StatusOffer, "offer_deadline": bson.M{"$gt": now, "$lte": now.Add(utils.OfferDeadlineNotice)}},
+	}}
+	cursor, err := applicationCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"resume_snapshot": 0}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var application models.JobApplication
+		if err := cursor.Decode(&application); err != nil {
+			return err
+		}
+		for _, reminder := range utils.DueReminders(application, now) {
+			reminder.ID = primitive.NewObjectID()
+			reminder.CreatedAt = now
+			if _, err := reminderCollection.InsertOne(ctx, reminder); err != nil && !mongo.IsDuplicateKeyError(err) {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// deliver claims pending reminders one at a time and hands them to the
+// notifier, so several server processes can run workers side by side.
+func (w *Worker) deliver(ctx context.Context, now time.Time) error {
+	for {
+		var reminder models.Reminder
+		claimedUntil := now.Add(claimLease)
+		err := reminderCollection.FindOneAndUpdate(ctx,
+			bson.M{
+				"delivered_at": nil,
+				"dismissed_at": nil,
+				"due_at":       bson.M{"$lte": now},
+				"attempts":     bson.M{"$lt": maxAttempts},
+				"$or": bson.A{
+					bson.M{"claimed_until": nil},
+					bson.M{"claimed_until": bson.M{"$lt": now}},
+				},
+			},
+			bson.M{"$set": bson.M{"claimed_until": claimedUntil}, "$inc": bson.M{"attempts": 1}},
+			options.FindOneAndUpdate().SetSort(bson.D{{"due_at", 1}}).SetReturnDocument(options.After),
+		).Decode(&reminder)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// A failed reminder keeps its claim, so it is retried on a later run
+		// once the lease is over rather than straight away.
+		update := bson.M{"$set": bson.M{"delivered_at": time.Now()}, "$unset": bson.M{"claimed_until": ""}}
+		if err := w.notify(ctx, reminder); err != nil {
+			log.Printf("reminders: delivering %s failed: %v", reminder.ID.Hex(), err)
+			update = bson.M{"$set": bson.M{"last_error": err.Error()}}
+		}
+		if _, err := reminderCollection.UpdateOne(ctx, bson.M{"_id": reminder.ID}, update); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Worker) notify(ctx context.Context, reminder models.Reminder) error {
+	var user models.User
+	if err := userCollection.FindOne(ctx, bson.M{"_id": reminder.UserID}).Decode(&user); err != nil {
+		return err
+	}
+	return w.Notifier.Notify(ctx, user, reminder)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func AnalyticsRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/analytics/funnel", controllers.GetApplicationFunnel())
+	incomingRoutes.GET("/users/:user_id/analytics/response-time", controllers.GetResponseTime())
+	incomingRoutes.GET("/users/:user_id/analytics/sources", controllers.GetResponseRateBySource())
+	incomingRoutes.GET("/users/:user_id/analytics/resumes", controllers.GetResponseRateByResume())
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func ContactRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/users/:user_id/contacts", controllers.CreateContact())
+	incomingRoutes.GET("/users/:user_id/contacts", controllers.GetContacts())
+	incomingRoutes.GET("/users/:user_id/contacts/stale", controllers.GetStaleContacts())
+	incomingRoutes.GET("/users/:user_id/contacts/:contact_id", controllers.GetContact())
+	incomingRoutes.PUT("/users/:user_id/contacts/:contact_id", controllers.UpdateContact())
+	incomingRoutes.DELETE("/users/:user_id/contacts/:contact_id", controllers.DeleteContact())
+	incomingRoutes.PUT("/users/:user_id/contacts/:contact_id/applications/:application_id", controllers.LinkContactApplication())
+	incomingRoutes.DELETE("/users/:user_id/contacts/:contact_id/applications/:application_id", controllers.UnlinkContactApplication())
+	incomingRoutes.POST("/users/:user_id/contacts/:contact_id/interactions", controllers.CreateInteraction())
+	incomingRoutes.GET("/users/:user_id/contacts/:contact_id/interactions", controllers.GetInteractions())
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func CoverLetterRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/cover-letters/templates", controllers.GetCoverLetterTemplates())
+	incomingRoutes.POST("/users/:user_id/cover-letters", controllers.CreateCoverLetter())
+	incomingRoutes.POST("/users/:user_id/cover-letters/generate", controllers.GenerateCoverLetter())
+	incomingRoutes.GET("/users/:user_id/cover-letters", controllers.GetCoverLetters())
+	incomingRoutes.GET("/users/:user_id/cover-letters/:cover_letter_id", controllers.GetCoverLetter())
+	incomingRoutes.PUT("/users/:user_id/cover-letters/:cover_letter_id", controllers.UpdateCoverLetter())
+	incomingRoutes.DELETE("/users/:user_id/cover-letters/:cover_letter_id", controllers.DeleteCoverLetter())
+	incomingRoutes.GET("/users/:user_id/cover-letters/:cover_letter_id/export", controllers.ExportCoverLetter())
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func DocumentRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/users/:user_id/documents", controllers.UploadDocument())
+	incomingRoutes.GET("/users/:user_id/documents", controllers.GetDocuments())
+	incomingRoutes.GET("/users/:user_id/documents/:document_id", controllers.GetDocument())
+	incomingRoutes.GET("/users/:user_id/documents/:document_id/download", controllers.DownloadDocument())
+	incomingRoutes.DELETE("/users/:user_id/documents/:document_id", controllers.DeleteDocument())
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func EmailRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/users/:user_id/emails/import", controllers.ImportEmails())
+	incomingRoutes.GET("/users/:user_id/emails/proposals", controllers.GetEmailProposals())
+	incomingRoutes.POST("/users/:user_id/emails/proposals/:proposal_id/confirm", controllers.ConfirmEmailProposal())
+	incomingRoutes.POST("/users/:user_id/emails/proposals/:proposal_id/dismiss", controllers.DismissEmailProposal())
+}
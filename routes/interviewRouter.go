@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func InterviewRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/interviews", controllers.GetInterviews())
+	incomingRoutes.POST("/users/:user_id/applications/:application_id/interviews", controllers.CreateInterview())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id/interviews/:interview_id", controllers.UpdateInterview())
+	incomingRoutes.DELETE("/users/:user_id/applications/:application_id/interviews/:interview_id", controllers.DeleteInterview())
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/interviews/:interview_id/ics", controllers.ExportInterview())
+	incomingRoutes.POST("/users/:user_id/calendar-feed", controllers.CreateCalendarFeed())
+	incomingRoutes.DELETE("/users/:user_id/calendar-feed", controllers.DeleteCalendarFeed())
+	incomingRoutes.GET("/shared/calendar/:token", controllers.GetCalendarFeed())
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func JobApplicationRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/applications/statuses", controllers.GetApplicationStatuses())
+	incomingRoutes.POST("/users/:user_id/applications", controllers.CreateApplication())
+	incomingRoutes.GET("/users/:user_id/applications", controllers.GetApplications())
+	incomingRoutes.GET("/users/:user_id/applications/board", controllers.GetApplicationBoard())
+	incomingRoutes.GET("/users/:user_id/applications/export", controllers.ExportApplications())
+	incomingRoutes.POST("/users/:user_id/applications/import/preview", controllers.PreviewApplicationImport())
+	incomingRoutes.POST("/users/:user_id/applications/import", controllers.ImportApplications())
+	incomingRoutes.GET("/users/:user_id/applications/:application_id", controllers.GetApplication())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id", controllers.UpdateApplication())
+	incomingRoutes.DELETE("/users/:user_id/applications/:application_id", controllers.DeleteApplication())
+	incomingRoutes.POST("/users/:user_id/applications/:application_id/transitions", controllers.TransitionApplication())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id/board", controllers.MoveApplication())
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/timeline", controllers.GetApplicationTimeline())
+	incomingRoutes.POST("/users/:user_id/applications/:application_id/notes", controllers.CreateApplicationNote())
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/notes", controllers.GetApplicationNotes())
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/notes/:note_id", controllers.GetApplicationNote())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id/notes/:note_id", controllers.UpdateApplicationNote())
+}
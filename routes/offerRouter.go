@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func OfferRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/applications/:application_id/offer", controllers.GetOffer())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id/offer", controllers.SetOffer())
+	incomingRoutes.DELETE("/users/:user_id/applications/:application_id/offer", controllers.DeleteOffer())
+	incomingRoutes.POST("/users/:user_id/offers/compare", controllers.CompareOffers())
+}
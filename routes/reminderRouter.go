@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func ReminderRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/reminders", controllers.GetReminders())
+	incomingRoutes.POST("/users/:user_id/reminders/:reminder_id/dismiss", controllers.DismissReminder())
+}
@@ -1 +1,29 @@
-package routes
\ No newline at end of file
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func ResumeRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/resumes", controllers.GetResumes())
+	incomingRoutes.GET("/users/:user_id/resumes/search", controllers.SearchResumes())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/export", controllers.ExportResume())
+	incomingRoutes.POST("/users/:user_id/resumes/:resume_id/shares", controllers.ShareResume())
+	incomingRoutes.DELETE("/users/:user_id/resumes/:resume_id/shares/:share_id", controllers.DeleteResumeShare())
+	incomingRoutes.POST("/users/:user_id/resumes/:resume_id/anonymize", controllers.AnonymizeResume())
+	incomingRoutes.POST("/users/:user_id/resumes/:resume_id/locales", controllers.CreateResumeLocale())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/locales", controllers.GetResumeLocales())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/locales/sync", controllers.CheckResumeLocaleSync())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/compare/:other_resume_id", controllers.CompareResumes())
+	incomingRoutes.POST("/users/:user_id/resumes/:resume_id/requirements", controllers.CheckResumeRequirements())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/layout", controllers.GetResumeLayout())
+	incomingRoutes.PUT("/users/:user_id/resumes/:resume_id/layout", controllers.UpdateResumeLayout())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/sections", controllers.GetCustomSections())
+	incomingRoutes.POST("/users/:user_id/resumes/:resume_id/sections", controllers.CreateCustomSection())
+	incomingRoutes.PUT("/users/:user_id/resumes/:resume_id/sections/order", controllers.ReorderCustomSections())
+	incomingRoutes.GET("/users/:user_id/resumes/:resume_id/sections/:section_id", controllers.GetCustomSection())
+	incomingRoutes.PUT("/users/:user_id/resumes/:resume_id/sections/:section_id", controllers.UpdateCustomSection())
+	incomingRoutes.DELETE("/users/:user_id/resumes/:resume_id/sections/:section_id", controllers.DeleteCustomSection())
+	incomingRoutes.GET("/shared/resumes/:token", controllers.GetSharedResume())
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func SavedJobRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.POST("/users/:user_id/jobs", controllers.CreateSavedJob())
+	incomingRoutes.POST("/users/:user_id/jobs/ingest", controllers.IngestJobPosting())
+	incomingRoutes.GET("/users/:user_id/jobs", controllers.GetSavedJobs())
+	incomingRoutes.GET("/users/:user_id/jobs/ranking", controllers.RankSavedJobs())
+	incomingRoutes.GET("/users/:user_id/jobs/:job_id", controllers.GetSavedJob())
+	incomingRoutes.PUT("/users/:user_id/jobs/:job_id", controllers.UpdateSavedJob())
+	incomingRoutes.DELETE("/users/:user_id/jobs/:job_id", controllers.DeleteSavedJob())
+	incomingRoutes.GET("/users/:user_id/jobs/:job_id/requirements", controllers.GetSavedJobRequirements())
+}
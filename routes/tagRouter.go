@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"crafter/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func TagRoutes(incomingRoutes *gin.Engine) {
+	incomingRoutes.GET("/users/:user_id/tags", controllers.GetTags())
+	incomingRoutes.PUT("/users/:user_id/tags/:tag", controllers.RenameTag())
+	incomingRoutes.DELETE("/users/:user_id/tags/:tag", controllers.DeleteTag())
+	incomingRoutes.PUT("/users/:user_id/resumes/:resume_id/tags", controllers.SetResumeTags())
+	incomingRoutes.PUT("/users/:user_id/applications/:application_id/tags", controllers.SetApplicationTags())
+	incomingRoutes.PUT("/users/:user_id/contacts/:contact_id/tags", controllers.SetContactTags())
+	incomingRoutes.PUT("/users/:user_id/jobs/:job_id/tags", controllers.SetSavedJobTags())
+	incomingRoutes.POST("/users/:user_id/filters", controllers.CreateSavedFilter())
+	incomingRoutes.GET("/users/:user_id/filters", controllers.GetSavedFilters())
+	incomingRoutes.GET("/users/:user_id/filters/:filter_id", controllers.GetSavedFilter())
+	incomingRoutes.PUT("/users/:user_id/filters/:filter_id", controllers.UpdateSavedFilter())
+	incomingRoutes.DELETE("/users/:user_id/filters/:filter_id", controllers.DeleteSavedFilter())
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrNotFound is returned by Get and Delete when no blob exists under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files. Keys are slash separated paths such as
+// "users/<user_id>/documents/<document_id>".
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Blobs is the store the document endpoints use. main sets it from
+// BlobInstance before the server starts.
+var Blobs BlobStore
+
+// BlobInstance builds the blob store selected by STORAGE_BACKEND. The local
+// filesystem is used unless "s3" is requested.
+func BlobInstance() (BlobStore, error) {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "s3":
+		store, err := NewS3Store(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crafter/models"
+	"math"
+	"sort"
+)
+
+// Rate divides part by whole, rounded to four decimals. An empty group has
+// a rate of zero rather than NaN.
+func Rate(part int, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+// Median returns the median of values, or false when there are none.
+func Median(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], true
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2, true
+}
+
+// BuildFunnel turns the number of applications that reached each of
+// models.FunnelStages into stage counts and step-by-step conversion rates.
+func BuildFunnel(reached []int) models.FunnelReport {
+	report := models.FunnelReport{
+		Stages:      []models.StageCount{},
+		Conversions: []models.StageConversion{},
+	}
+	for i, status := range models.FunnelStages {
+		count := 0
+		if i < len(reached) {
+			count = reached[i]
+		}
+		report.Stages = append(report.Stages, models.StageCount{Status: status, Count: count})
+	}
+	for i := 1; i < len(report.Stages); i++ {
+		from, to := report.Stages[i-1], report.Stages[i]
+		report.Conversions = append(report.Conversions, models.StageConversion{
+			From:      from.Status,
+			To:        to.Status,
+			Reached:   from.Count,
+			Converted: to.Count,
+			Rate:      Rate(to.Count, from.Count),
+		})
+	}
+	return report
+}
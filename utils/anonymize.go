@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AnonymousName     = "Candidate"
+	AnonymousEmail    = "[email removed]"
+	AnonymousPhone    = "[phone removed]"
+	AnonymousLocation = "[location removed]"
+	AnonymousLink     = "[link removed]"
+	AnonymousCollege  = "[college removed]"
+	AnonymousDate     = "[date removed]"
+)
+
+var pronounPattern = regexp.MustCompile(`(?i)\b(he|she|him|her|his|hers|himself|herself)\b`)
+var nextWordPattern = regexp.MustCompile(`^\s+([A-Za-z]+)`)
+var previousWordPattern = regexp.MustCompile(`([A-Za-z]+)\s+$`)
+
+// Words that follow "her" used as an object: "gave her the award",
+// "mentored her and".
+var objectFollowers = wordSet("a", "an", "the", "and", "or", "but", "nor", "so", "to", "in", "on", "at", "for",
+	"with", "by", "from", "as", "into", "onto", "through", "about", "after", "before", "during", "over", "up",
+	"out", "off", "down", "back", "again", "when", "while", "if", "how", "what", "that", "this", "these", "those")
+
+// Words before "her" that make the next word one of her things: "with her
+// manager", "to her team".
+var possessivePrecursors = wordSet("to", "for", "with", "by", "from", "of", "about", "under", "alongside", "on",
+	"in", "at", "into", "through", "during", "among", "without", "like", "and", "or", "but")
+
+// Verbs after which "her" and the next word can be either an object and a
+// verb or a possessive and a noun: "helped her finish" but "helped her team".
+var ambiguousPrecursors = wordSet("help", "helps", "helped", "helping", "let", "lets", "letting", "make",
+	"makes", "made", "making", "have", "has", "had", "see", "saw", "seen", "watch", "watched", "hear", "heard",
+	"give", "gives", "gave", "given", "giving", "show", "shows", "showed", "shown", "teach", "teaches", "taught",
+	"tell", "tells", "told", "offer", "offered", "send", "sent", "bring", "brought", "get", "got", "pay", "paid",
+	"award", "awarded", "grant", "granted", "encourage", "encouraged", "ask", "asked", "want", "wanted")
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// AnonymizeResume returns a blind-hiring copy of the resume together with a
+// report of every value that was replaced.
+func AnonymizeResume(resume models.Resume, options models.AnonymizationOptions) models.AnonymizedResume {
+	var substitutions []models.Substitution
+	substitute := func(field string, original string, replacement string) string {
+		if original == "" || original == replacement {
+			return original
+		}
+		substitutions = append(substitutions, models.Substitution{
+			Field:       field,
+			Original:    original,
+			Replacement: replacement,
+		})
+		return replacement
+	}
+	substituteLink := func(field string, link *string) *string {
+		if link == nil || *link == "" {
+			return link
+		}
+		placeholder := substitute(field, *link, AnonymousLink)
+		return &placeholder
+	}
+	substituteDate := func(field string, date time.Time) time.Time {
+		if date.IsZero() {
+			return date
+		}
+		// The zero date is left out of the JSON, see models.Education.
+		substitute(field, date.Format("2006-01-02"), AnonymousDate)
+		return time.Time{}
+	}
+
+	resume.Name = substitute("name", resume.Name, AnonymousName)
+	resume.Email = substitute("email", resume.Email, AnonymousEmail)
+	resume.PhoneNumber = substitute("phone_number", resume.PhoneNumber, AnonymousPhone)
+	resume.Location = substitute("location", resume.Location, AnonymousLocation)
+	resume.LinkedInLink = substituteLink("linkedin_link", resume.LinkedInLink)
+	resume.GitHubLink = substituteLink("github_link", resume.GitHubLink)
+	resume.PortfolioLink = substituteLink("portfolio_link", resume.PortfolioLink)
+
+	var review []models.PronounReview
+	neutralize := func(field string, text string) string {
+		neutral, ambiguous := neutralizePronouns(text)
+		if ambiguous {
+			review = append(review, models.PronounReview{Field: field, Text: text})
+		}
+		return substitute(field, text, neutral)
+	}
+	neutralizeAll := func(field string, texts []string) []string {
+		if texts == nil {
+			return nil
+		}
+		neutral := make([]string, len(texts))
+		for i, text := range texts {
+			neutral[i] = neutralize(fmt.Sprintf("%s[%d]", field, i), text)
+		}
+		return neutral
+	}
+
+	// The copy must not point back at the owner or their other resumes.
+	resume.ID = primitive.NilObjectID
+	resume.UserID = primitive.NilObjectID
+	resume.LocaleGroupID = primitive.NilObjectID
+	resume.Tags = nil
+	resume.Layout = nil
+
+	if resume.Summary != nil {
+		summary := neutralize("summary", *resume.Summary)
+		resume.Summary = &summary
+	}
+
+	education := make([]models.Education, len(resume.Education))
+	copy(education, resume.Education)
+	for i := range education {
+		path := fmt.Sprintf("education[%d]", i)
+		education[i].StartDate = substituteDate(path+".start_date", education[i].StartDate)
+		education[i].EndDate = substituteDate(path+".end_date", education[i].EndDate)
+		education[i].ExpectedGraduationDate = substituteDate(path+".expected_graduation_date", education[i].ExpectedGraduationDate)
+		if options.HideColleges {
+			education[i].Name = substitute(path+".name", education[i].Name, AnonymousCollege)
+			education[i].Location = substitute(path+".location", education[i].Location, AnonymousLocation)
+		}
+	}
+	resume.Education = education
+
+	work := make([]models.WorkExperience, len(resume.WorkExperience))
+	copy(work, resume.WorkExperience)
+	for i := range work {
+		path := fmt.Sprintf("work_experience[%d]", i)
+		work[i].Location = substitute(path+".location", work[i].Location, AnonymousLocation)
+		work[i].BulletPoints = neutralizeAll(path+".bullet_points", work[i].BulletPoints)
+	}
+	resume.WorkExperience = work
+
+	projects := make([]models.Project, len(resume.Projects))
+	copy(projects, resume.Projects)
+	for i := range projects {
+		path := fmt.Sprintf("projects[%d]", i)
+		if projects[i].Description != nil {
+			description := neutralize(path+".description", *projects[i].Description)
+			projects[i].Description = &description
+		}
+		projects[i].ProjectUrl = substituteLink(path+".project_url", projects[i].ProjectUrl)
+		projects[i].BulletPoints = neutralizeAll(path+".bullet_points", projects[i].BulletPoints)
+	}
+	resume.Projects = projects
+
+	certifications := make([]models.Certification, len(resume.Certifications))
+	copy(certifications, resume.Certifications)
+	for i := range certifications {
+		path := fmt.Sprintf("certifications[%d]", i)
+		certifications[i].Description = neutralize(path+".description", certifications[i].Description)
+		certifications[i].CertificateLink = substitute(path+".certificate_link", certifications[i].CertificateLink, AnonymousLink)
+	}
+	resume.Certifications = certifications
+
+	awards := make([]models.HonorAward, len(resume.HonorsAwards))
+	copy(awards, resume.HonorsAwards)
+	for i := range awards {
+		path := fmt.Sprintf("honors_awards[%d]", i)
+		awards[i].Title = neutralize(path+".title", awards[i].Title)
+		awards[i].Description = neutralize(path+".description", awards[i].Description)
+	}
+	resume.HonorsAwards = awards
+
+	activities := make([]models.Extracurricular, len(resume.Extracurriculars))
+	copy(activities, resume.Extracurriculars)
+	for i := range activities {
+		path := fmt.Sprintf("extracurriculars[%d]", i)
+		activities[i].ActivityName = neutralize(path+".activity_name", activities[i].ActivityName)
+		activities[i].Description = neutralize(path+".description", activities[i].Description)
+	}
+	resume.Extracurriculars = activities
+
+	sections := make([]models.CustomSection, len(resume.CustomSections))
+	copy(sections, resume.CustomSections)
+	for i := range sections {
+		entries := make([]models.CustomEntry, len(sections[i].Entries))
+		copy(entries, sections[i].Entries)
+		for j := range entries {
+			path := fmt.Sprintf("custom_sections[%d].entries[%d]", i, j)
+			entries[j].Text = neutralize(path+".text", entries[j].Text)
+			entries[j].Title = neutralize(path+".title", entries[j].Title)
+			entries[j].Subtitle = neutralize(path+".subtitle", entries[j].Subtitle)
+			entries[j].Description = neutralize(path+".description", entries[j].Description)
+			entries[j].Value = neutralize(path+".value", entries[j].Value)
+		}
+		sections[i].Entries = entries
+	}
+	resume.CustomSections = sections
+
+	if substitutions == nil {
+		substitutions = []models.Substitution{}
+	}
+	if review == nil {
+		review = []models.PronounReview{}
+	}
+	return models.AnonymizedResume{Resume: resume, Substitutions: substitutions, Review: review}
+}
+
+// NeutralizePronouns replaces gendered pronouns with their singular "they"
+// forms, keeping the original capitalization. "his" becomes "their" when
+// followed by a word and "theirs" otherwise. "her" becomes "them" at the end
+// of a clause or before a word that follows an object, and "their" at the
+// start of a sentence, after a preposition or after any other verb. After a
+// verb like "helped", where both readings work, it is left as written.
+func NeutralizePronouns(text string) string {
+	neutral, _ := neutralizePronouns(text)
+	return neutral
+}
+
+// neutralizePronouns is NeutralizePronouns that also reports whether a
+// pronoun was left as written because its reading was ambiguous.
+func neutralizePronouns(text string) (string, bool) {
+	var b strings.Builder
+	ambiguous := false
+	last := 0
+	for _, match := range pronounPattern.FindAllStringIndex(text, -1) {
+		word := text[match[0]:match[1]]
+		var nextWord, previousWord string
+		if next := nextWordPattern.FindStringSubmatch(text[match[1]:]); next != nil {
+			nextWord = strings.ToLower(next[1])
+		}
+		if previous := previousWordPattern.FindStringSubmatch(text[:match[0]]); previous != nil {
+			previousWord = strings.ToLower(previous[1])
+		}
+
+		var replacement string
+		switch strings.ToLower(word) {
+		case "he", "she":
+			replacement = "they"
+		case "him":
+			replacement = "them"
+		case "her":
+			switch {
+			case nextWord == "" || objectFollowers[nextWord]:
+				replacement = "them"
+			case previousWord == "" || possessivePrecursors[previousWord]:
+				replacement = "their"
+			case ambiguousPrecursors[previousWord]:
+				ambiguous = true
+				replacement = strings.ToLower(word)
+			default:
+				replacement = "their"
+			}
+		case "his":
+			replacement = "theirs"
+			if nextWord != "" {
+				replacement = "their"
+			}
+		case "hers":
+			replacement = "theirs"
+		case "himself", "herself":
+			replacement = "themselves"
+		}
+
+		b.WriteString(text[last:match[0]])
+		b.WriteString(matchCase(word, replacement))
+		last = match[1]
+	}
+	b.WriteString(text[last:])
+	return b.String(), ambiguous
+}
+
+func matchCase(original string, replacement string) string {
+	runes := []rune(original)
+	if len(runes) > 1 && strings.ToUpper(original) == original {
+		return strings.ToUpper(replacement)
+	}
+	if unicode.IsUpper(runes[0]) {
+		return strings.ToUpper(replacement[:1]) + replacement[1:]
+	}
+	return replacement
+}
@@ -0,0 +1,566 @@
+package utils
+
+import (
+	"bytes"
+	"crafter/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxImportRows caps the rows of one import. Real trackers are well below
+// it; anything larger is more likely the wrong file.
+const MaxImportRows = 2000
+
+var ErrEmptyCSV = errors.New("the file has no header row")
+
+// CSVRecord is a data row of a CSV file and the line it starts on.
+type CSVRecord struct {
+	Line   int
+	Fields []string
+}
+
+// ReadCSV reads a spreadsheet export. It accepts a UTF-8 byte order mark
+// and comma, semicolon or tab separators, which is what Excel, Google
+// Sheets and Numbers produce depending on locale. Blank rows are skipped.
+func ReadCSV(r io.Reader) ([]string, []CSVRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffDelimiter(data)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var headers []string
+	records := []CSVRecord{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if blankRecord(fields) {
+			continue
+		}
+		if headers == nil {
+			headers = make([]string, len(fields))
+			for i, header := range fields {
+				if headers[i] = strings.TrimSpace(header); headers[i] == "" {
+					headers[i] = fmt.Sprintf("column %d", i+1)
+				}
+			}
+			continue
+		}
+		if len(records) == MaxImportRows {
+			return nil, nil, fmt.Errorf("the file has more than %d rows", MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, CSVRecord{Line: line, Fields: fields})
+	}
+	if headers == nil {
+		return nil, nil, ErrEmptyCSV
+	}
+	return headers, records, nil
+}
+
+// sniffDelimiter picks the separator that occurs most in the first line.
+func sniffDelimiter(data []byte) rune {
+	first := data
+	if end := bytes.IndexByte(data, '\n'); end >= 0 {
+		first = data[:end]
+	}
+	delimiter, best := ',', bytes.Count(first, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(first, []byte(string(candidate))); n > best {
+			delimiter, best = candidate, n
+		}
+	}
+	return delimiter
+}
+
+func blankRecord(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importHeaderAliases lists the column names other trackers and common
+// spreadsheet templates use for each field, normalised by normalizeHeader.
+var importHeaderAliases = map[models.ImportField][]string{
+	models.ImportCompanyName:    {"companyname", "company", "employer", "organization", "organisation", "org"},
+	models.ImportRoleTitle:      {"roletitle", "role", "jobtitle", "title", "position", "positiontitle", "job"},
+	models.ImportStatus:         {"status", "stage", "applicationstatus", "state"},
+	models.ImportAppliedAt:      {"appliedat", "dateapplied", "applieddate", "appliedon", "applicationdate", "applied", "date"},
+	models.ImportSource:         {"source", "jobboard", "foundon", "foundvia", "via", "channel"},
+	models.ImportLocation:       {"location", "joblocation", "city"},
+	models.ImportJobURL:         {"joburl", "url", "link", "joblink", "postingurl", "jobposting", "posting"},
+	models.ImportSalaryMin:      {"salarymin", "minsalary", "salaryfrom", "salarylow", "salary"},
+	models.ImportSalaryMax:      {"salarymax", "maxsalary", "salaryto", "salaryhigh"},
+	models.ImportSalaryCurrency: {"salarycurrency", "currency"},
+	models.ImportSalaryPeriod:   {"salaryperiod", "payperiod", "period"},
+	models.ImportOfferDeadline:  {"offerdeadline", "deadline", "respondby"},
+	models.ImportNotes:          {"notes", "note", "comments", "comment"},
+}
+
+func normalizeHeader(header string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SuggestImportMapping guesses which column holds each field. Earlier
+// aliases win, and each column is used for at most one field.
+func SuggestImportMapping(headers []string) models.ImportMapping {
+	byName := map[string]string{}
+	for _, header := range headers {
+		name := normalizeHeader(header)
+		if _, ok := byName[name]; !ok {
+			byName[name] = header
+		}
+	}
+
+	mapping := models.ImportMapping{}
+	used := map[string]bool{}
+	for _, field := range models.ImportFields {
+		for _, alias := range importHeaderAliases[field] {
+			if header, ok := byName[alias]; ok && !used[header] {
+				mapping[field] = header
+				used[header] = true
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// CheckImportMapping makes sure a mapping only names known fields and
+// columns that are in the file.
+func CheckImportMapping(mapping models.ImportMapping, headers []string) error {
+	known := map[models.ImportField]bool{}
+	for _, field := range models.ImportFields {
+		known[field] = true
+	}
+	columns := map[string]bool{}
+	for _, header := range headers {
+		columns[header] = true
+	}
+
+	for field, column := range mapping {
+		if !known[field] {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if column != "" && !columns[column] {
+			return fmt.Errorf("column %q mapped to %s is not in the file", column, field)
+		}
+	}
+	return nil
+}
+
+// MissingImportFields lists the fields every application needs that the
+// mapping leaves out.
+func MissingImportFields(mapping models.ImportMapping) []string {
+	missing := []string{}
+	for _, field := range []models.ImportField{models.ImportCompanyName, models.ImportRoleTitle} {
+		if mapping[field] == "" {
+			missing = append(missing, string(field))
+		}
+	}
+	return missing
+}
+
+var numericDate = regexp.MustCompile(`^(\d{1,2})[/.\-](\d{1,2})[/.\-](\d{2}|\d{4})(?:[ T].*)?$`)
+
+var excelSerialDate = regexp.MustCompile(`^\d{5}(?:\.\d+)?$`)
+
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Jan 2 2006",
+	"January 2 2006",
+	"Mon, Jan 2, 2006",
+	"Monday, January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"02-Jan-2006",
+	"2-Jan-06",
+	"Jan 2006",
+	"January 2006",
+}
+
+// DetectDayFirst looks for a numeric date that only reads one way, such
+// as 25/03/2024. It reports false for decided when every date fits both.
+func DetectDayFirst(values []string) (dayFirst bool, decided bool) {
+	for _, value := range values {
+		match := numericDate.FindStringSubmatch(strings.TrimSpace(value))
+		if match == nil {
+			continue
+		}
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[2])
+		if first > 12 && second <= 12 {
+			return true, true
+		}
+		if second > 12 && first <= 12 {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// ParseImportDate reads the date formats spreadsheets end up with: ISO
+// dates, numeric dates in either order, dates with month names and Excel
+// serial day numbers. Dates without a zone are taken as UTC.
+func ParseImportDate(value string, dayFirst bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if match := numericDate.FindStringSubmatch(value); match != nil {
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[2])
+		year, _ := strconv.Atoi(match[3])
+		if len(match[3]) == 2 {
+			year += 2000
+		}
+		day, month := second, first
+		if dayFirst {
+			day, month = first, second
+		}
+		t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if month < 1 || month > 12 || t.Day() != day {
+			return time.Time{}, fmt.Errorf("%q is not a valid date", value)
+		}
+		return t, nil
+	}
+
+	if excelSerialDate.MatchString(value) {
+		days, _ := strconv.ParseFloat(value, 64)
+		// Excel counts days from 30 December 1899.
+		epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+		return epoch.AddDate(0, 0, int(days)), nil
+	}
+
+	normalized := titleWords(value)
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a recognised date", value)
+}
+
+// titleWords capitalises the first letter of each word and lowers the
+// rest, since time.Parse only matches month names written as "Jan".
+func titleWords(value string) string {
+	runes := []rune(strings.ToLower(value))
+	for i, r := range runes {
+		if i == 0 || !unicode.IsLetter(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// importStatusAliases maps the status names other trackers use, normalised
+// by normalizeHeader, to application statuses.
+var importStatusAliases = map[string]models.ApplicationStatus{
+	"wishlist":           models.StatusSaved,
+	"bookmarked":         models.StatusSaved,
+	"interested":         models.StatusSaved,
+	"toapply":            models.StatusSaved,
+	"notapplied":         models.StatusSaved,
+	"draft":              models.StatusSaved,
+	"submitted":          models.StatusApplied,
+	"applicationsent":    models.StatusApplied,
+	"sent":               models.StatusApplied,
+	"pending":            models.StatusApplied,
+	"waiting":            models.StatusApplied,
+	"inreview":           models.StatusScreening,
+	"underreview":        models.StatusScreening,
+	"screen":             models.StatusScreening,
+	"phonescreen":        models.StatusScreening,
+	"recruiterscreen":    models.StatusScreening,
+	"recruitercall":      models.StatusScreening,
+	"hrcall":             models.StatusScreening,
+	"assessment":         models.StatusScreening,
+	"onlineassessment":   models.StatusScreening,
+	"interview":          models.StatusInterviewing,
+	"interviews":         models.StatusInterviewing,
+	"onsite":             models.StatusInterviewing,
+	"technicalinterview": models.StatusInterviewing,
+	"finalround":         models.StatusInterviewing,
+	"offered":            models.StatusOffer,
+	"offerreceived":      models.StatusOffer,
+	"hired":              models.StatusAccepted,
+	"offeraccepted":      models.StatusAccepted,
+	"signed":             models.StatusAccepted,
+	"rejection":          models.StatusRejected,
+	"declined":           models.StatusRejected,
+	"notselected":        models.StatusRejected,
+	"nooffer":            models.StatusRejected,
+	"withdrew":           models.StatusWithdrawn,
+	"offerdeclined":      models.StatusWithdrawn,
+	"noresponse":         models.StatusGhosted,
+	"noreply":            models.StatusGhosted,
+	"unresponsive":       models.StatusGhosted,
+}
+
+func ParseImportStatus(value string) (models.ApplicationStatus, error) {
+	name := normalizeHeader(value)
+	for _, status := range models.ApplicationStatuses {
+		if name == string(status) {
+			return status, nil
+		}
+	}
+	if status, ok := importStatusAliases[name]; ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("%q is not a known status", value)
+}
+
+var currencySymbols = map[string]string{"$": "USD", "€": "EUR", "£": "GBP", "₹": "INR", "¥": "JPY"}
+
+var importSalaryPeriods = map[string]string{
+	"year": "year", "yearly": "year", "annual": "year", "annually": "year", "peryear": "year", "yr": "year", "pa": "year",
+	"month": "month", "monthly": "month", "permonth": "month", "mo": "month",
+	"hour": "hour", "hourly": "hour", "perhour": "hour", "hr": "hour",
+}
+
+var salaryRange = regexp.MustCompile(`^(.*?\d[kKmM]?)\s*(?:-|–|—|to)\s*(.*\d.*)$`)
+
+// parseAmount reads "120,000", "$120k" or "1.2M". The currency is returned
+// when a known symbol or code is part of the value.
+func parseAmount(value string) (float64, string, error) {
+	text := strings.TrimSpace(value)
+	currency := ""
+	for symbol, code := range currencySymbols {
+		if strings.Contains(text, symbol) {
+			currency = code
+			text = strings.ReplaceAll(text, symbol, "")
+		}
+	}
+	if fields := strings.Fields(text); len(fields) == 2 && len(fields[1]) == 3 {
+		currency, text = strings.ToUpper(fields[1]), fields[0]
+	}
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", "")
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(strings.ToLower(text), "k"):
+		multiplier, text = 1000, text[:len(text)-1]
+	case strings.HasSuffix(strings.ToLower(text), "m"):
+		multiplier, text = 1000000, text[:len(text)-1]
+	}
+	amount, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q is not an amount", value)
+	}
+	return amount * multiplier, currency, nil
+}
+
+// importCell strips the quote that WriteApplicationsCSV puts in front of
+// values a spreadsheet would otherwise run as a formula.
+func importCell(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// ParseImportRecord turns a CSV row into an application using the mapping.
+// The application is not validated; problems the parser finds are
+// returned as row errors.
+func ParseImportRecord(headers []string, record CSVRecord, mapping models.ImportMapping, dayFirst bool) models.ImportRow {
+	row := models.ImportRow{Row: record.Line, Errors: []models.ImportRowError{}}
+	index := map[string]int{}
+	for i, header := range headers {
+		if _, ok := index[header]; !ok {
+			index[header] = i
+		}
+	}
+	get := func(field models.ImportField) string {
+		i, ok := index[mapping[field]]
+		if !ok || mapping[field] == "" || i >= len(record.Fields) {
+			return ""
+		}
+		return importCell(record.Fields[i])
+	}
+	fail := func(field models.ImportField, err error) {
+		row.Errors = append(row.Errors, models.ImportRowError{
+			Row:     record.Line,
+			Field:   field,
+			Column:  mapping[field],
+			Message: err.Error(),
+		})
+	}
+	date := func(field models.ImportField) *time.Time {
+		value := get(field)
+		if value == "" {
+			return nil
+		}
+		t, err := ParseImportDate(value, dayFirst)
+		if err != nil {
+			fail(field, err)
+			return nil
+		}
+		return &t
+	}
+
+	application := models.JobApplication{
+		CompanyName: get(models.ImportCompanyName),
+		RoleTitle:   get(models.ImportRoleTitle),
+		Source:      get(models.ImportSource),
+		Location:    get(models.ImportLocation),
+		JobURL:      get(models.ImportJobURL),
+	}
+	application.AppliedAt = date(models.ImportAppliedAt)
+	application.OfferDeadline = date(models.ImportOfferDeadline)
+
+	if value := get(models.ImportStatus); value != "" {
+		status, err := ParseImportStatus(value)
+		if err != nil {
+			fail(models.ImportStatus, err)
+		}
+		application.Status = status
+	} else if application.AppliedAt != nil {
+		application.Status = models.StatusApplied
+	} else {
+		application.Status = models.StatusSaved
+	}
+
+	minText, maxText := get(models.ImportSalaryMin), get(models.ImportSalaryMax)
+	if match := salaryRange.FindStringSubmatch(minText); match != nil && maxText == "" {
+		// A single salary column often holds the whole range.
+		minText, maxText = match[1], match[2]
+	}
+	if minText != "" || maxText != "" {
+		salary := models.SalaryRange{Period: "year"}
+		var minCurrency, maxCurrency string
+		var err error
+		if minText != "" {
+			if salary.Min, minCurrency, err = parseAmount(minText); err != nil {
+				fail(models.ImportSalaryMin, err)
+			}
+		}
+		if maxText != "" {
+			if salary.Max, maxCurrency, err = parseAmount(maxText); err != nil {
+				fail(models.ImportSalaryMax, err)
+			}
+		}
+		if minText == "" {
+			salary.Min = salary.Max
+		}
+		if maxText == "" {
+			salary.Max = salary.Min
+		}
+
+		salary.Currency = strings.ToUpper(get(models.ImportSalaryCurrency))
+		if salary.Currency == "" {
+			salary.Currency = minCurrency
+		}
+		if salary.Currency == "" {
+			salary.Currency = maxCurrency
+		}
+		if salary.Currency == "" {
+			fail(models.ImportSalaryCurrency, errors.New("salary has no currency"))
+		}
+		if value := get(models.ImportSalaryPeriod); value != "" {
+			if salary.Period = importSalaryPeriods[normalizeHeader(value)]; salary.Period == "" {
+				fail(models.ImportSalaryPeriod, fmt.Errorf("%q is not a salary period", value))
+			}
+		}
+		application.Salary = &salary
+	}
+
+	row.Application = application
+	row.Note = get(models.ImportNotes)
+	return row
+}
+
+// ApplicationDuplicateKey identifies an application by company and role,
+// ignoring case and spacing.
+func ApplicationDuplicateKey(company string, role string) string {
+	return strings.ToLower(collapseSpace(company)) + "\x00" + strings.ToLower(collapseSpace(role))
+}
+
+// csvSafe quotes values a spreadsheet would run as a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// WriteApplicationsCSV writes applications with the columns of
+// models.ApplicationCSVColumns.
+func WriteApplicationsCSV(w io.Writer, applications []models.JobApplication) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(models.ApplicationCSVColumns); err != nil {
+		return err
+	}
+	for _, application := range applications {
+		var salaryMin, salaryMax, currency, period string
+		if application.Salary != nil {
+			salaryMin = strconv.FormatFloat(application.Salary.Min, 'f', -1, 64)
+			salaryMax = strconv.FormatFloat(application.Salary.Max, 'f', -1, 64)
+			currency = application.Salary.Currency
+			period = application.Salary.Period
+		}
+		createdAt, updatedAt := application.CreatedAt, application.UpdatedAt
+		record := []string{
+			application.ID.Hex(),
+			csvSafe(application.CompanyName),
+			csvSafe(application.RoleTitle),
+			string(application.Status),
+			csvTime(application.AppliedAt),
+			csvSafe(application.Source),
+			csvSafe(application.Location),
+			csvSafe(application.JobURL),
+			salaryMin,
+			salaryMax,
+			currency,
+			period,
+			csvTime(application.OfferDeadline),
+			csvTime(&createdAt),
+			csvTime(&updatedAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
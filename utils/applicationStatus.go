@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"time"
+)
+
+// CanTransition reports whether an application may move from one status to
+// another.
+func CanTransition(from models.ApplicationStatus, to models.ApplicationStatus) bool {
+	for _, allowed := range models.ApplicationTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanStartIn reports whether a new application may be created with the
+// given status.
+func CanStartIn(status models.ApplicationStatus) bool {
+	for _, allowed := range models.InitialStatuses {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StartApplication fills in the status history of a new application. The
+// first entry is dated by applied_at when the user gives one, so changes
+// that happened since can still be recorded with their real dates. It
+// returns an error for an applied_at in the future.
+func StartApplication(application *models.JobApplication, now time.Time) error {
+	if application.AppliedAt != nil && application.AppliedAt.After(now) {
+		return fmt.Errorf("applied_at cannot be in the future")
+	}
+	if application.AppliedAt == nil && application.Status != models.StatusSaved {
+		application.AppliedAt = &now
+	}
+
+	at := now
+	if application.AppliedAt != nil {
+		at = *application.AppliedAt
+	}
+	application.StatusHistory = []models.StatusChange{{To: application.Status, At: at}}
+	return nil
+}
+
+// LastStatusChange returns when the application last changed status, or
+// the zero time if it has no history.
+func LastStatusChange(application models.JobApplication) time.Time {
+	if n := len(application.StatusHistory); n > 0 {
+		return application.StatusHistory[n-1].At
+	}
+	return time.Time{}
+}
+
+// TransitionApplication moves an application to a new status and appends
+// the change to its history. It returns an error for moves the pipeline
+// does not allow and for changes dated before the previous one, which
+// would put the history out of order, and leaves the application untouched
+// in that case.
+func TransitionApplication(application *models.JobApplication, to models.ApplicationStatus, note string, at time.Time) (models.StatusChange, error) {
+	from := application.Status
+	if !CanTransition(from, to) {
+		return models.StatusChange{}, fmt.Errorf("cannot move an application from %s to %s", from, to)
+	}
+	if last := LastStatusChange(*application); at.Before(last) {
+		return models.StatusChange{}, fmt.Errorf("a transition cannot be dated before the previous status change on %s", last.Format(time.RFC3339))
+	}
+
+	change := models.StatusChange{From: from, To: to, At: at, Note: note}
+	application.Status = to
+	application.StatusHistory = append(application.StatusHistory, change)
+	if to == models.StatusApplied && application.AppliedAt == nil {
+		application.AppliedAt = &at
+	}
+	return change, nil
+}
+
+// TransitionPath returns the shortest series of statuses that takes an
+// application from one status to another, e.g. saved, applied, rejected
+// for a rejection that arrives before the user marked the application as
+// applied. It reports false when the pipeline allows no way there.
+func TransitionPath(from models.ApplicationStatus, to models.ApplicationStatus) ([]models.ApplicationStatus, bool) {
+	if from == to {
+		return nil, false
+	}
+	previous := map[models.ApplicationStatus]models.ApplicationStatus{from: from}
+	queue := []models.ApplicationStatus{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range models.ApplicationTransitions[current] {
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			previous[next] = current
+			if next == to {
+				path := []models.ApplicationStatus{}
+				for status := to; status != from; status = previous[status] {
+					path = append([]models.ApplicationStatus{status}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
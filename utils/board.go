@@ -0,0 +1,37 @@
+package utils
+
+// BoardPositionGap is the spacing between cards when a column is numbered
+// from scratch.
+const BoardPositionGap = 1024.0
+
+// BoardPosition returns the position that places a card at index in a
+// column whose other cards have the given positions, sorted ascending. The
+// second result is false when the neighbours are too close together to fit
+// a value between them and the column has to be renumbered first.
+func BoardPosition(positions []float64, index int) (float64, bool) {
+	if len(positions) == 0 {
+		return BoardPositionGap, true
+	}
+	if index <= 0 {
+		return positions[0] - BoardPositionGap, true
+	}
+	if index >= len(positions) {
+		return positions[len(positions)-1] + BoardPositionGap, true
+	}
+
+	before, after := positions[index-1], positions[index]
+	position := before + (after-before)/2
+	if position <= before || position >= after {
+		return 0, false
+	}
+	return position, true
+}
+
+// RenumberBoardPositions spreads count cards evenly, keeping their order.
+func RenumberBoardPositions(count int) []float64 {
+	positions := make([]float64, count)
+	for i := range positions {
+		positions[i] = float64(i+1) * BoardPositionGap
+	}
+	return positions
+}
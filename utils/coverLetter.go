@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"crafter/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const DefaultCoverLetterTemplate = "standard"
+
+// MaxCoverLetterLength is the longest text a template may render, the same
+// limit models.CoverLetter puts on a stored body.
+const MaxCoverLetterLength = 20000
+
+// maxTemplateRangeDepth caps how deeply range blocks may be nested. Every
+// level multiplies the work a template does.
+const maxTemplateRangeDepth = 2
+
+var errCoverLetterTooLong = fmt.Errorf("cover letter is longer than %d bytes", MaxCoverLetterLength)
+
+// CoverLetterTemplates are the built-in templates. They use the fields of
+// models.CoverLetterMergeFields plus the "join" function.
+var CoverLetterTemplates = map[string]string{
+	"standard": `{{.Date}}
+
+Dear Hiring Manager{{if .TargetCompany}} at {{.TargetCompany}}{{end}},
+
+I am writing to apply for the {{if .TargetRole}}{{.TargetRole}}{{else}}open{{end}} position{{if .TargetCompany}} at {{.TargetCompany}}{{end}}.{{if .TopRole}} As {{.TopRole}} at {{.TopCompany}}, I have built the experience this role asks for.{{end}}
+{{if .TopBullets}}
+Recent highlights include:
+{{range .TopBullets}}- {{.}}
+{{end}}{{end}}{{if .MatchingSkills}}
+My background in {{join .MatchingSkills ", "}} matches what you are looking for.{{else if .Skills}}
+My core skills include {{join .Skills ", "}}.{{end}}
+
+I would welcome the opportunity to discuss how I can contribute to your team.
+
+Sincerely,
+{{.FullName}}
+`,
+	"short": `Dear Hiring Manager,
+
+I would like to be considered for the {{if .TargetRole}}{{.TargetRole}}{{else}}open{{end}} role{{if .TargetCompany}} at {{.TargetCompany}}{{end}}.{{if .TopRole}} I currently work as {{.TopRole}} at {{.TopCompany}}.{{end}}{{if .MatchingSkills}} My experience with {{join .MatchingSkills ", "}} is a close fit for the position.{{end}}
+
+Best regards,
+{{.FullName}}
+`,
+	"career_change": `{{.Date}}
+
+Dear Hiring Manager{{if .TargetCompany}} at {{.TargetCompany}}{{end}},
+
+I am excited to apply for the {{if .TargetRole}}{{.TargetRole}}{{else}}open{{end}} position. {{if .TopRole}}While my recent work as {{.TopRole}} at {{.TopCompany}} sits in a different field, it{{else}}My background{{end}} has given me skills that carry over directly{{if .Skills}}, including {{join .Skills ", "}}{{end}}.
+{{if .TopBullets}}
+For example:
+{{range .TopBullets}}- {{.}}
+{{end}}{{end}}
+I am eager to bring this perspective to your team and would appreciate the chance to talk.
+
+Sincerely,
+{{.FullName}}
+`,
+}
+
+var coverLetterFuncs = template.FuncMap{"join": strings.Join}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// BuildCoverLetterMergeFields collects the template values from the user,
+// the optional resume and the optional target job. The most recent job on
+// the resume counts as the top work experience.
+func BuildCoverLetterMergeFields(user models.User, resume *models.Resume, job *models.TargetJob, now time.Time) models.CoverLetterMergeFields {
+	fields := models.CoverLetterMergeFields{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		FullName:  strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Email:     user.Email,
+		Date:      now.Format("January 2, 2006"),
+	}
+	if user.CurrentCompany != nil {
+		fields.CurrentCompany = *user.CurrentCompany
+	}
+
+	if resume != nil {
+		visible := ApplyResumeLayout(*resume)
+		if fields.FullName == "" {
+			fields.FullName = visible.Name
+		}
+		fields.Skills = visible.Skills
+
+		var top *models.WorkExperience
+		for i := range visible.WorkExperience {
+			work := &visible.WorkExperience[i]
+			if top == nil || work.IsWorking && !top.IsWorking || work.IsWorking == top.IsWorking && work.StartDate.After(top.StartDate) {
+				top = work
+			}
+		}
+		if top != nil {
+			fields.TopRole = top.RoleTitle
+			fields.TopCompany = top.CompanyName
+			fields.TopBullets = top.BulletPoints
+			if len(fields.TopBullets) > 3 {
+				fields.TopBullets = fields.TopBullets[:3]
+			}
+		}
+	}
+	if fields.TopCompany == "" {
+		fields.TopCompany = fields.CurrentCompany
+	}
+
+	if job != nil {
+		fields.TargetCompany = job.CompanyName
+		fields.TargetRole = job.RoleTitle
+
+		described := map[string]bool{}
+		for _, word := range textTokens(job.JobDescription) {
+			described[word] = true
+		}
+		for _, skill := range fields.Skills {
+			words := textTokens(skill)
+			found := len(words) > 0
+			for _, word := range words {
+				found = found && described[word]
+			}
+			if found {
+				fields.MatchingSkills = append(fields.MatchingSkills, skill)
+			}
+		}
+	}
+
+	return fields
+}
+
+// GenerateCoverLetter fills a template with the merge fields. Unknown fields
+// in the template are reported as errors rather than rendered empty.
+// Templates come from users, so they may only loop over merge fields, may
+// not call other templates and must render within MaxCoverLetterLength.
+func GenerateCoverLetter(templateText string, fields models.CoverLetterMergeFields) (string, error) {
+	tmpl, err := template.New("cover_letter").Funcs(coverLetterFuncs).Option("missingkey=error").Parse(templateText)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return "", fmt.Errorf("invalid template: templates cannot define other templates")
+	}
+	if err := checkTemplateNode(tmpl.Tree.Root, 0); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	b := &limitedWriter{limit: MaxCoverLetterLength}
+	if err := tmpl.Execute(b, fields); err != nil {
+		if errors.Is(err, errCoverLetterTooLong) {
+			return "", errCoverLetterTooLong
+		}
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	// Optional blocks that rendered empty leave runs of blank lines behind.
+	return strings.TrimSpace(blankLines.ReplaceAllString(b.String(), "\n\n")) + "\n", nil
+}
+
+// checkTemplateNode walks a parsed template and rejects the constructs that
+// could make it run for long: calls to other templates, range blocks over
+// anything but a merge field and range blocks nested too deeply.
+func checkTemplateNode(node parse.Node, depth int) error {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			if err := checkTemplateNode(child, depth); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		if err := checkTemplateNode(node.List, depth); err != nil {
+			return err
+		}
+		return checkTemplateNode(node.ElseList, depth)
+	case *parse.WithNode:
+		if err := checkTemplateNode(node.List, depth); err != nil {
+			return err
+		}
+		return checkTemplateNode(node.ElseList, depth)
+	case *parse.RangeNode:
+		if depth >= maxTemplateRangeDepth {
+			return fmt.Errorf("range blocks can be nested at most %d deep", maxTemplateRangeDepth)
+		}
+		if !rangesOverField(node.Pipe) {
+			return errors.New("range can only loop over a merge field such as .TopBullets")
+		}
+		if err := checkTemplateNode(node.List, depth+1); err != nil {
+			return err
+		}
+		return checkTemplateNode(node.ElseList, depth)
+	case *parse.TemplateNode:
+		return errors.New("templates cannot call other templates")
+	}
+	return nil
+}
+
+// rangesOverField reports whether a range pipeline is a plain field such as
+// .Skills or $.Skills, so the loop is bounded by the merge fields.
+func rangesOverField(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1 && arg.Ident[0] == "$"
+	}
+	return false
+}
+
+// limitedWriter collects template output and fails once it grows past the
+// limit, which stops the template from running any further.
+type limitedWriter struct {
+	strings.Builder
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		return 0, errCoverLetterTooLong
+	}
+	return w.Builder.Write(p)
+}
+
+// RenderCoverLetter renders a cover letter in one of the resume export
+// formats.
+func RenderCoverLetter(letter models.CoverLetter, format string) ([]byte, string, error) {
+	switch format {
+	case "", JSONFormat:
+		body, err := json.Marshal(letter)
+		return body, "application/json; charset=utf-8", err
+	case MarkdownFormat:
+		return []byte(fmt.Sprintf("# %s\n\n%s", letter.Title, letter.Body)), "text/markdown; charset=utf-8", nil
+	}
+	return nil, "", fmt.Errorf("unsupported export format %q", format)
+}
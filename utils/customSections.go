@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateCustomSection checks that every entry carries the fields its
+// section type needs. Field lengths are covered by the struct tags.
+func ValidateCustomSection(section models.CustomSection) error {
+	for i, entry := range section.Entries {
+		switch section.Type {
+		case models.BulletsSection:
+			if strings.TrimSpace(entry.Text) == "" {
+				return fmt.Errorf("entry %d: text is required for bullets sections", i)
+			}
+		case models.DatedSection:
+			if strings.TrimSpace(entry.Title) == "" {
+				return fmt.Errorf("entry %d: title is required for dated sections", i)
+			}
+			if entry.StartDate.IsZero() {
+				return fmt.Errorf("entry %d: start_date is required for dated sections", i)
+			}
+			if !entry.EndDate.IsZero() && entry.EndDate.Before(entry.StartDate) {
+				return fmt.Errorf("entry %d: end_date is before start_date", i)
+			}
+		case models.KeyValueSection:
+			if strings.TrimSpace(entry.Key) == "" || strings.TrimSpace(entry.Value) == "" {
+				return fmt.Errorf("entry %d: key and value are required for key_value sections", i)
+			}
+		default:
+			return fmt.Errorf("unknown section type %q", section.Type)
+		}
+	}
+	return nil
+}
+
+// SortedCustomSections returns the custom sections in their display order.
+func SortedCustomSections(sections []models.CustomSection) []models.CustomSection {
+	sorted := make([]models.CustomSection, len(sections))
+	copy(sorted, sections)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+	return sorted
+}
+
+// NextCustomSectionPosition returns the position that puts a new section
+// after all existing ones.
+func NextCustomSectionPosition(sections []models.CustomSection) int {
+	position := 0
+	for _, section := range sections {
+		if section.Position >= position {
+			position = section.Position + 1
+		}
+	}
+	return position
+}
+
+// ReorderCustomSections returns a copy of the sections with their positions
+// set to the order of ids, which must name every section exactly once.
+func ReorderCustomSections(sections []models.CustomSection, ids []primitive.ObjectID) ([]models.CustomSection, error) {
+	positions := map[primitive.ObjectID]int{}
+	for i, id := range ids {
+		if _, seen := positions[id]; seen {
+			return nil, fmt.Errorf("section_ids contains duplicates")
+		}
+		positions[id] = i
+	}
+	if len(positions) != len(sections) {
+		return nil, fmt.Errorf("section_ids must list every section of the resume")
+	}
+
+	reordered := make([]models.CustomSection, len(sections))
+	copy(reordered, sections)
+	for i := range reordered {
+		position, ok := positions[reordered[i].ID]
+		if !ok {
+			return nil, fmt.Errorf("section_ids must list every section of the resume")
+		}
+		reordered[i].Position = position
+	}
+	return reordered, nil
+}
+
+func writeCustomSection(b *strings.Builder, section models.CustomSection, locale resumeLocale) {
+	for _, entry := range section.Entries {
+		switch section.Type {
+		case models.BulletsSection:
+			fmt.Fprintf(b, "- %s\n", entry.Text)
+		case models.DatedSection:
+			fmt.Fprintf(b, "### %s\n", entry.Title)
+			if line := joinNonEmpty(" | ", entry.Subtitle, locale.formatDateRange(entry.StartDate, entry.EndDate, false)); line != "" {
+				fmt.Fprintf(b, "%s\n", line)
+			}
+			if entry.Description != "" {
+				fmt.Fprintf(b, "%s\n", entry.Description)
+			}
+			b.WriteString("\n")
+		case models.KeyValueSection:
+			fmt.Fprintf(b, "- **%s:** %s\n", entry.Key, entry.Value)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crafter/models"
+	"net/url"
+	"path"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LinkDocument turns a resume URL saved before uploads existed into a
+// document record. It has no stored file; downloading it redirects to the
+// URL.
+func LinkDocument(userId primitive.ObjectID, link string, at time.Time) models.Document {
+	fileName := link
+	if parsed, err := url.Parse(link); err == nil {
+		if base := path.Base(parsed.Path); base != "." && base != "/" {
+			fileName = base
+		} else if parsed.Host != "" {
+			fileName = parsed.Host
+		}
+	}
+	return models.Document{
+		ID:          primitive.NewObjectID(),
+		UserID:      userId,
+		FileName:    fileName,
+		URL:         link,
+		Description: "Resume link",
+		CreatedAt:   at,
+	}
+}
@@ -0,0 +1,472 @@
+package utils
+
+import (
+	"bytes"
+	"crafter/models"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxEmailText caps the body text kept per message. The phrases the
+// classifier looks for are near the top; quoted replies further down only
+// add noise.
+const maxEmailText = 64 << 10
+
+// maxMultipartDepth stops parsing multipart messages nested deeper than
+// any real mail client produces.
+const maxMultipartDepth = 5
+
+var mboxSeparator = regexp.MustCompile(`(?m)^From \S+.*\r?\n`)
+
+var mboxQuotedFrom = regexp.MustCompile(`(?m)^>(>*From )`)
+
+// SplitMbox splits an mbox archive into raw messages. A "From " line only
+// starts a message at the top of the file or after a blank line, and the
+// ">From " quoting of body lines is undone.
+func SplitMbox(data []byte) [][]byte {
+	var starts [][]int
+	for _, loc := range mboxSeparator.FindAllIndex(data, -1) {
+		before := data[:loc[0]]
+		if loc[0] == 0 || bytes.HasSuffix(before, []byte("\n\n")) || bytes.HasSuffix(before, []byte("\r\n\r\n")) {
+			starts = append(starts, loc)
+		}
+	}
+
+	messages := [][]byte{}
+	for i, loc := range starts {
+		end := len(data)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		message := mboxQuotedFrom.ReplaceAll(data[loc[1]:end], []byte("$1"))
+		if len(bytes.TrimSpace(message)) > 0 {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// ParseEmails reads an uploaded .eml file or mbox archive. Messages that
+// cannot be read are reported by position and skipped.
+func ParseEmails(data []byte) ([]models.EmailMessage, []string) {
+	raws := [][]byte{data}
+	if bytes.HasPrefix(data, []byte("From ")) {
+		raws = SplitMbox(data)
+	}
+
+	messages := []models.EmailMessage{}
+	problems := []string{}
+	for i, raw := range raws {
+		message, err := ParseEmail(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("message %d: %v", i+1, err))
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, problems
+}
+
+// ParseEmail reads one RFC 5322 message. The text is the plain text body,
+// or the HTML body converted to text when there is no plain one.
+func ParseEmail(raw []byte) (models.EmailMessage, error) {
+	var message models.EmailMessage
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return message, err
+	}
+
+	from, err := parsed.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return message, errors.New("missing or invalid From header")
+	}
+	message.From = strings.ToLower(from[0].Address)
+	message.FromName = from[0].Name
+
+	decoder := mime.WordDecoder{CharsetReader: charsetReader}
+	message.Subject = parsed.Header.Get("Subject")
+	if subject, err := decoder.DecodeHeader(message.Subject); err == nil {
+		message.Subject = subject
+	}
+	if date, err := parsed.Header.Date(); err == nil {
+		message.Date = date
+	}
+
+	message.Text, _, err = emailBodyText(parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body, 0)
+	if err != nil {
+		return message, err
+	}
+	if len(message.Text) > maxEmailText {
+		cut := maxEmailText
+		for cut > 0 && !utf8.RuneStart(message.Text[cut]) {
+			cut--
+		}
+		message.Text = message.Text[:cut]
+	}
+
+	// Messages without an id still need a stable key so uploading the same
+	// archive twice does not propose everything again.
+	message.MessageID = strings.Trim(parsed.Header.Get("Message-Id"), "<> \t")
+	if message.MessageID == "" {
+		sum := sha256.Sum256([]byte(message.From + "\x00" + message.Subject + "\x00" + message.Date.String() + "\x00" + message.Text))
+		message.MessageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return message, nil
+}
+
+// emailBodyText decodes a message body and returns its text and whether
+// it came from HTML. In multipart messages plain text parts are preferred
+// and attachments are skipped.
+func emailBodyText(contentType string, encoding string, body io.Reader, depth int) (string, bool, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || contentType == "" {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMultipartDepth {
+			return "", false, nil
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		var plain, html string
+		for {
+			// NextPart undoes quoted-printable itself and drops the header.
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if plain == "" && html == "" {
+					return "", false, fmt.Errorf("invalid multipart body: %w", err)
+				}
+				break
+			}
+			if strings.HasPrefix(strings.ToLower(part.Header.Get("Content-Disposition")), "attachment") {
+				continue
+			}
+			text, isHTML, err := emailBodyText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil || strings.TrimSpace(text) == "" {
+				continue
+			}
+			if isHTML && html == "" {
+				html = text
+			} else if !isHTML && plain == "" {
+				plain = text
+			}
+		}
+		if plain != "" {
+			return plain, false, nil
+		}
+		return html, html != "", nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, 4*maxEmailText))
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s body: %w", encoding, err)
+	}
+
+	text := decodeCharset(data, params["charset"])
+	if mediaType == "text/html" {
+		return HTMLToText(text), true, nil
+	}
+	return text, false, nil
+}
+
+// decodeCharset converts the charsets recruiting mail actually uses to
+// UTF-8. Anything else is read as UTF-8 with invalid bytes dropped.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return string(bytes.ToValidUTF8(data, nil))
+	}
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(decodeCharset(data, charset)), nil
+}
+
+// emailKindCues lists the phrases of each kind of email, most decisive
+// kind first: a rejection often thanks the candidate for applying or
+// interviewing, so it has to win over those.
+var emailKindCues = []struct {
+	kind models.EmailKind
+	cues []string
+}{
+	{models.RejectionEmail, []string{
+		"regret to inform", "not to move forward", "not be moving forward", "will not be moving forward",
+		"won't be moving forward", "not moving forward with your", "move forward with other candidates",
+		"decided to pursue other candidates", "pursue other candidates", "other candidates whose",
+		"not been selected", "were not selected", "position has been filled", "no longer under consideration",
+		"not be progressing", "not progress your application", "unable to offer you", "decided not to proceed",
+		"will not be proceeding",
+	}},
+	{models.OfferEmail, []string{
+		"pleased to offer", "happy to offer", "delighted to offer", "excited to offer", "offer of employment",
+		"offer letter", "extend an offer", "extend you an offer", "formal offer", "verbal offer",
+	}},
+	{models.InterviewEmail, []string{
+		"schedule an interview", "schedule a call", "schedule a time", "invite you to interview",
+		"invite you to an interview", "invitation to interview", "interview invitation", "like to interview you",
+		"next round", "phone screen", "technical interview", "onsite interview", "your availability",
+		"book a time", "calendly.com", "set up a call", "online assessment", "coding assessment",
+	}},
+	{models.ConfirmationEmail, []string{
+		"thank you for applying", "thanks for applying", "thank you for your application",
+		"thanks for your application", "application has been received", "received your application",
+		"application was received", "application received", "successfully submitted",
+		"thank you for your interest in",
+	}},
+}
+
+// screeningCues mark an interview invite that is only a first screen.
+var screeningCues = []string{
+	"phone screen", "recruiter call", "screening call", "intro call", "introductory call",
+	"online assessment", "coding assessment", "hackerrank", "codility",
+}
+
+func containsPhrase(normalized string, phrase string) bool {
+	return strings.Contains(" "+normalized+" ", " "+normalizeText(phrase)+" ")
+}
+
+// ClassifyEmail decides what an email says about an application and the
+// status that implies. The cues are the phrases it was decided on.
+func ClassifyEmail(message models.EmailMessage) (models.EmailKind, models.ApplicationStatus, []string) {
+	text := normalizeText(message.Subject + "\n" + message.Text)
+	for _, group := range emailKindCues {
+		cues := []string{}
+		for _, cue := range group.cues {
+			if containsPhrase(text, cue) {
+				cues = append(cues, cue)
+			}
+		}
+		if len(cues) == 0 {
+			continue
+		}
+
+		switch group.kind {
+		case models.RejectionEmail:
+			return group.kind, models.StatusRejected, cues
+		case models.OfferEmail:
+			return group.kind, models.StatusOffer, cues
+		case models.InterviewEmail:
+			for _, cue := range screeningCues {
+				if containsPhrase(text, cue) {
+					return group.kind, models.StatusScreening, cues
+				}
+			}
+			return group.kind, models.StatusInterviewing, cues
+		default:
+			return group.kind, models.StatusApplied, cues
+		}
+	}
+	return models.UnknownEmail, "", []string{}
+}
+
+// sharedMailDomains send mail for many companies, so the sender domain says
+// nothing about which company wrote: applicant tracking systems, job
+// boards and personal mail providers.
+var sharedMailDomains = []string{
+	"greenhouse.io", "greenhouse-mail.io", "lever.co", "myworkday.com", "myworkdayjobs.com", "workday.com",
+	"smartrecruiters.com", "ashbyhq.com", "icims.com", "jobvite.com", "bamboohr.com", "successfactors.com",
+	"taleo.net", "workable.com", "workablemail.com", "recruitee.com", "breezy.hr", "teamtailor.com",
+	"linkedin.com", "indeed.com", "glassdoor.com", "gmail.com", "googlemail.com", "outlook.com",
+	"hotmail.com", "yahoo.com", "icloud.com",
+}
+
+// genericDomainLabels are parts of a company's mail domain that are not
+// its name.
+var genericDomainLabels = map[string]bool{
+	"com": true, "co": true, "io": true, "net": true, "org": true, "uk": true, "us": true, "de": true,
+	"in": true, "ai": true, "app": true, "dev": true, "tech": true, "mail": true, "email": true, "jobs": true,
+	"careers": true, "career": true, "hr": true, "talent": true, "recruiting": true, "recruitment": true,
+	"hire": true, "hiring": true, "notifications": true, "notification": true, "noreply": true, "team": true,
+}
+
+var legalSuffixes = map[string]bool{
+	"inc": true, "llc": true, "ltd": true, "limited": true, "corp": true, "corporation": true, "co": true,
+	"gmbh": true, "plc": true, "ag": true, "sa": true, "bv": true, "pvt": true, "private": true, "company": true,
+}
+
+// companyWords returns the words of a company name without a legal suffix
+// such as "Inc.", which mail from the company usually leaves out.
+func companyWords(name string) []string {
+	tokens := textTokens(name)
+	for len(tokens) > 1 && legalSuffixes[strings.Trim(tokens[len(tokens)-1], ".")] {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// companyKey reduces a company name to the form its domain usually takes:
+// "Acme Widgets, Inc." becomes "acmewidgets".
+func companyKey(name string) string {
+	var b strings.Builder
+	for _, token := range companyWords(name) {
+		for _, r := range token {
+			if r != '.' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+func isSharedMailDomain(domain string) bool {
+	for _, shared := range sharedMailDomains {
+		if domain == shared || strings.HasSuffix(domain, "."+shared) {
+			return true
+		}
+	}
+	return false
+}
+
+// domainMatchesCompany reports whether a mail or job posting domain
+// belongs to the company, allowing for names like acme-corp.com or
+// mail.acmehq.com.
+func domainMatchesCompany(domain string, key string) bool {
+	if key == "" || domain == "" || isSharedMailDomain(domain) {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		label = strings.ReplaceAll(label, "-", "")
+		if genericDomainLabels[label] || label == "" {
+			continue
+		}
+		if label == key || (len(key) >= 4 && strings.HasPrefix(label, key)) || (len(label) >= 4 && strings.HasPrefix(key, label)) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailMatch is the application an email most likely belongs to.
+type EmailMatch struct {
+	Application *models.JobApplication
+	// Candidates are the applications that tie for the best match when
+	// there is no single one.
+	Candidates []models.JobApplication
+	Reasons    []string
+}
+
+// MatchEmailApplication finds the application an email is about. The
+// company has to be recognised from the sender domain or named in the
+// sender, subject or body; the role title then separates applications at
+// the same company.
+func MatchEmailApplication(message models.EmailMessage, applications []models.JobApplication) EmailMatch {
+	domain := message.From[strings.LastIndex(message.From, "@")+1:]
+	header := normalizeText(message.FromName + "\n" + message.Subject)
+	body := normalizeText(message.Text)
+
+	type scored struct {
+		application models.JobApplication
+		company     int
+		role        int
+		reasons     []string
+	}
+	matches := []scored{}
+	for _, application := range applications {
+		match := scored{application: application}
+		key := companyKey(application.CompanyName)
+		name := strings.Join(companyWords(application.CompanyName), " ")
+
+		switch {
+		case domainMatchesCompany(domain, key):
+			match.company = 3
+			match.reasons = append(match.reasons, "sender domain "+domain+" matches "+application.CompanyName)
+		case name != "" && containsPhrase(header, name):
+			match.company = 2
+			match.reasons = append(match.reasons, application.CompanyName+" is named in the sender or subject")
+		case name != "" && containsPhrase(body, name):
+			match.company = 1
+			match.reasons = append(match.reasons, application.CompanyName+" is named in the message")
+		}
+		if match.company == 0 && application.JobURL != "" {
+			if posting, err := url.Parse(application.JobURL); err == nil && strings.EqualFold(posting.Hostname(), domain) {
+				match.company = 3
+				match.reasons = append(match.reasons, "sender domain matches the job posting")
+			}
+		}
+		if match.company == 0 {
+			continue
+		}
+
+		if containsPhrase(header, application.RoleTitle) || containsPhrase(body, application.RoleTitle) {
+			match.role = 2
+			match.reasons = append(match.reasons, "role "+application.RoleTitle+" is named")
+		} else {
+			words := textTokens(application.RoleTitle)
+			found := 0
+			for _, word := range words {
+				if containsPhrase(header, word) || containsPhrase(body, word) {
+					found++
+				}
+			}
+			if len(words) > 0 && found*2 >= len(words) {
+				match.role = 1
+				match.reasons = append(match.reasons, "role "+application.RoleTitle+" partly matches")
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	// Naming the company only in the body is too weak on its own; it has to
+	// be backed by the role.
+	strong := matches[:0]
+	for _, match := range matches {
+		if match.company+match.role >= 2 {
+			strong = append(strong, match)
+		}
+	}
+	if len(strong) == 0 {
+		return EmailMatch{Candidates: []models.JobApplication{}, Reasons: []string{}}
+	}
+	sort.SliceStable(strong, func(i, j int) bool {
+		return strong[i].company+strong[i].role > strong[j].company+strong[j].role
+	})
+
+	best := strong[0].company + strong[0].role
+	tied := []models.JobApplication{}
+	for _, match := range strong {
+		if match.company+match.role == best {
+			tied = append(tied, match.application)
+		}
+	}
+	if len(tied) > 1 {
+		return EmailMatch{Candidates: tied, Reasons: []string{fmt.Sprintf("%d applications match equally well", len(tied))}}
+	}
+	application := strong[0].application
+	return EmailMatch{Application: &application, Candidates: []models.JobApplication{}, Reasons: strong[0].reasons}
+}
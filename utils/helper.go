@@ -97,29 +97,40 @@ func UpdateAllTokens(signedToken string, signedRefreshToken string, userId strin
 	return nil
 }
 
+// ValidateToken checks the signature and expiry of a token and returns
+// its claims. msg says why a token was refused.
 func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
+	if SECRET_KEY == "" {
+		msg = "token signing key is not configured"
+		return nil, msg
+	}
 
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&SignedDetails{},
 		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
 			return []byte(SECRET_KEY), nil
 		},
 	)
 
-	//the token is invalid
-	claims, ok := token.Claims.(*SignedDetails)
-	if !ok {
-		msg = fmt.Sprintf("the token is invalid")
-		msg = err.Error()
-		return
+	//the token is expired
+	if validationErr, ok := err.(*jwt.ValidationError); ok && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+		msg = "token is expired"
+		return nil, msg
 	}
 
-	//the token is expired
-	if claims.ExpiresAt < time.Now().Local().Unix() {
-		msg = fmt.Sprint("token is expired")
-		msg = err.Error()
-		return
+	//the token is invalid
+	if err != nil || !token.Valid {
+		msg = "the token is invalid"
+		return nil, msg
+	}
+	claims, ok := token.Claims.(*SignedDetails)
+	if !ok || claims.ExpiresAt < time.Now().Local().Unix() {
+		msg = "the token is invalid"
+		return nil, msg
 	}
 
 	return claims, msg
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	// Interviews name their zone, so the zone database has to be there
+	// even on hosts without one installed.
+	_ "time/tzdata"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// ValidateInterview checks the interview's time zone and stores StartsAt
+// in UTC.
+func ValidateInterview(interview *models.Interview) error {
+	if _, err := time.LoadLocation(interview.TimeZone); err != nil || interview.TimeZone == "Local" {
+		return fmt.Errorf("unknown time zone %q", interview.TimeZone)
+	}
+	interview.StartsAt = interview.StartsAt.UTC()
+	return nil
+}
+
+// InterviewEnd returns when an interview is scheduled to finish.
+func InterviewEnd(interview models.Interview) time.Time {
+	return interview.StartsAt.Add(time.Duration(interview.DurationMinutes) * time.Minute)
+}
+
+// RenderInterviewCalendar renders the interviews of the given applications
+// as an iCalendar (RFC 5545) document, one event per interview round.
+func RenderInterviewCalendar(name string, applications []models.JobApplication) []byte {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Crafter//Interviews//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+icalEscape(name))
+
+	type event struct {
+		application models.JobApplication
+		interview   models.Interview
+	}
+	events := []event{}
+	for _, application := range applications {
+		for _, interview := range application.Interviews {
+			events = append(events, event{application, interview})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].interview.StartsAt.Before(events[j].interview.StartsAt)
+	})
+
+	for _, e := range events {
+		writeInterviewEvent(&b, e.application, e.interview)
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func writeInterviewEvent(b *strings.Builder, application models.JobApplication, interview models.Interview) {
+	writeICalLine(b, "BEGIN:VEVENT")
+	writeICalLine(b, "UID:"+interview.ID.Hex()+"@crafter")
+	writeICalLine(b, "DTSTAMP:"+interview.UpdatedAt.UTC().Format(icalTimeFormat))
+	writeICalLine(b, "DTSTART:"+interview.StartsAt.UTC().Format(icalTimeFormat))
+	writeICalLine(b, "DTEND:"+InterviewEnd(interview).UTC().Format(icalTimeFormat))
+	writeICalLine(b, fmt.Sprintf("SEQUENCE:%d", interview.Sequence))
+	writeICalLine(b, "SUMMARY:"+icalEscape(fmt.Sprintf("%s: %s at %s", interview.Round, application.RoleTitle, application.CompanyName)))
+	if interview.Location != "" {
+		writeICalLine(b, "LOCATION:"+icalEscape(interview.Location))
+	}
+	if application.JobURL != "" {
+		writeICalLine(b, "URL:"+application.JobURL)
+	}
+	writeICalLine(b, "DESCRIPTION:"+icalEscape(interviewDescription(interview)))
+	writeICalLine(b, "END:VEVENT")
+}
+
+func interviewDescription(interview models.Interview) string {
+	lines := []string{"Format: " + strings.ReplaceAll(string(interview.Format), "_", " ")}
+	if location, err := time.LoadLocation(interview.TimeZone); err == nil {
+		local := interview.StartsAt.In(location)
+		lines = append(lines, fmt.Sprintf("Local time: %s (%s)", local.Format("Mon Jan 2 2006 15:04 MST"), interview.TimeZone))
+	}
+	if len(interview.Interviewers) > 0 {
+		names := []string{}
+		for _, interviewer := range interview.Interviewers {
+			names = append(names, joinNonEmpty(", ", interviewer.Name, interviewer.Title))
+		}
+		lines = append(lines, "Interviewers: "+strings.Join(names, "; "))
+	}
+	if interview.PrepNote != "" {
+		lines = append(lines, "", "Prep notes:", interview.PrepNote)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// icalEscape escapes a TEXT value.
+func icalEscape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+// writeICalLine writes a content line, folding it so that no line is longer
+// than 75 octets without splitting a UTF-8 character.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weights of the fit factors. They add up to 100, so a fit score reads as
+// a percentage.
+const (
+	SkillsFitWeight    = 60
+	SeniorityFitWeight = 25
+	LocationFitWeight  = 15
+)
+
+// experienceRank places the user's self-reported experience on the same
+// scale as seniorityRank.
+var experienceRank = map[models.ExperienceLevel]int{
+	models.Fresher:     0,
+	models.EntryLevel:  1,
+	models.MidLevel:    2,
+	models.SeniorLevel: 3,
+}
+
+var seniorityRank = map[models.SeniorityLevel]int{
+	models.EntrySeniority:  1,
+	models.MidSeniority:    2,
+	models.SeniorSeniority: 3,
+	models.LeadSeniority:   4,
+}
+
+// ScoreJobFit scores how well a resume fits a saved job. The caller stamps
+// the result with the ids and update times it was computed from.
+func ScoreJobFit(job models.SavedJob, resume models.Resume, experience models.ExperienceLevel, now time.Time) models.JobFit {
+	requirements := ExtractRequirements(job.Title, job.Description, resume.Skills)
+	report := CheckRequirements(requirements, resume, now)
+
+	fit := models.JobFit{
+		MatchedSkills: []string{},
+		MissingSkills: []string{},
+	}
+	skills := skillsFit(report, &fit)
+	fit.Components = []models.FitComponent{
+		skills,
+		seniorityFit(requirements, experience),
+		locationFit(job, resume.Location),
+	}
+
+	total := 0.0
+	for _, component := range fit.Components {
+		total += component.Score * float64(component.Weight)
+	}
+	fit.Score = int(math.Round(total))
+	return fit
+}
+
+func skillsFit(report models.RequirementReport, fit *models.JobFit) models.FitComponent {
+	component := models.FitComponent{Factor: models.SkillsFit, Weight: SkillsFitWeight}
+
+	var must, mustTotal, nice, niceTotal float64
+	for _, check := range report.Checklist {
+		if check.Category != models.SkillRequirement {
+			continue
+		}
+		credit := 0.0
+		switch check.Status {
+		case models.RequirementMet:
+			credit = 1
+			fit.MatchedSkills = append(fit.MatchedSkills, check.Requirement)
+		case models.RequirementPartial:
+			credit = 0.5
+			fit.MatchedSkills = append(fit.MatchedSkills, check.Requirement)
+		default:
+			fit.MissingSkills = append(fit.MissingSkills, check.Requirement)
+		}
+		if check.Required {
+			must += credit
+			mustTotal++
+		} else {
+			nice += credit
+			niceTotal++
+		}
+	}
+
+	switch {
+	case mustTotal == 0 && niceTotal == 0:
+		component.Score = 0.5
+		component.Explanation = "no recognised skills in the job description"
+		return component
+	case mustTotal == 0:
+		component.Score = nice / niceTotal
+	case niceTotal == 0:
+		component.Score = must / mustTotal
+	default:
+		// Nice-to-have skills can lift a score but should not sink one.
+		component.Score = 0.8*must/mustTotal + 0.2*nice/niceTotal
+	}
+
+	parts := []string{}
+	if mustTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %d required skills", formatCredit(must), int(mustTotal)))
+	}
+	if niceTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%s of %d nice-to-have skills", formatCredit(nice), int(niceTotal)))
+	}
+	component.Explanation = "matches " + strings.Join(parts, " and ")
+	if len(fit.MissingSkills) > 0 {
+		component.Explanation += "; missing " + strings.Join(fit.MissingSkills, ", ")
+	}
+	return component
+}
+
+// formatCredit prints a skill count, where a skill only mentioned in
+// experience counts as half.
+func formatCredit(credit float64) string {
+	if credit == math.Trunc(credit) {
+		return fmt.Sprintf("%.0f", credit)
+	}
+	return fmt.Sprintf("%.1f", credit)
+}
+
+func seniorityFit(requirements models.JobRequirements, experience models.ExperienceLevel) models.FitComponent {
+	component := models.FitComponent{Factor: models.SeniorityFit, Weight: SeniorityFitWeight}
+
+	level := requirements.Seniority
+	if level == "" && requirements.MinYearsExperience > 0 {
+		for _, candidate := range []models.SeniorityLevel{models.EntrySeniority, models.MidSeniority, models.SeniorSeniority, models.LeadSeniority} {
+			if float64(requirements.MinYearsExperience) >= seniorityYears[candidate] {
+				level = candidate
+			}
+		}
+	}
+	userRank, known := experienceRank[experience]
+	if level == "" || !known {
+		component.Score = 0.7
+		component.Explanation = "seniority could not be compared"
+		return component
+	}
+
+	gap := seniorityRank[level] - userRank
+	switch {
+	case gap <= 0 && gap >= -1:
+		component.Score = 1
+		component.Explanation = fmt.Sprintf("%s role suits %s experience", level, experience)
+	case gap < -1:
+		component.Score = 0.6
+		component.Explanation = fmt.Sprintf("%s role is below %s experience", level, experience)
+	case gap == 1:
+		component.Score = 0.5
+		component.Explanation = fmt.Sprintf("%s role is a step up from %s experience", level, experience)
+	default:
+		component.Score = 0.1
+		component.Explanation = fmt.Sprintf("%s role is well above %s experience", level, experience)
+	}
+	return component
+}
+
+func locationFit(job models.SavedJob, location string) models.FitComponent {
+	component := models.FitComponent{Factor: models.LocationFit, Weight: LocationFitWeight}
+
+	if job.Remote || strings.Contains(strings.ToLower(job.Location), "remote") {
+		component.Score = 1
+		component.Explanation = "remote role"
+		return component
+	}
+
+	jobParts := locationParts(job.Location)
+	resumeParts := locationParts(location)
+	if len(jobParts) == 0 || len(resumeParts) == 0 {
+		component.Score = 0.5
+		component.Explanation = "location could not be compared"
+		return component
+	}
+
+	// Locations are written most specific first, so the first parts are
+	// the city and the last part is the country.
+	switch {
+	case jobParts[0] == resumeParts[0]:
+		component.Score = 1
+		component.Explanation = "same city as the resume (" + job.Location + ")"
+	case sharesPart(jobParts, resumeParts):
+		component.Score = 0.6
+		component.Explanation = "same region or country as the resume, different city (" + job.Location + ")"
+	default:
+		component.Score = 0.1
+		component.Explanation = "relocation needed (" + job.Location + ")"
+	}
+	return component
+}
+
+func locationParts(location string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(location, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func sharesPart(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RankJobFits orders jobs by fit score, best first. Ties keep the most
+// recently saved job first.
+func RankJobFits(jobs []models.SavedJob, fits map[string]models.JobFit) []models.RankedJob {
+	ranked := []models.RankedJob{}
+	for _, job := range jobs {
+		ranked = append(ranked, models.RankedJob{Job: job, Fit: fits[job.ID.Hex()]})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Fit.Score != ranked[j].Fit.Score {
+			return ranked[i].Fit.Score > ranked[j].Fit.Score
+		}
+		return ranked[i].Job.CreatedAt.After(ranked[j].Job.CreatedAt)
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
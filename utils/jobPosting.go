@@ -0,0 +1,441 @@
+package utils
+
+import (
+	"crafter/models"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoJobPosting is returned when a page has neither JobPosting markup
+// nor anything that looks like a job title.
+var ErrNoJobPosting = errors.New("no job posting found in the page")
+
+// salaryPeriods maps schema.org unitText values to SalaryRange periods.
+var salaryPeriods = map[string]string{
+	"YEAR":  "year",
+	"MONTH": "month",
+	"HOUR":  "hour",
+}
+
+// pageInfo is what the parser collects from a page in a single pass.
+type pageInfo struct {
+	jsonLD          []string
+	title           string
+	heading         string
+	ogTitle         string
+	siteName        string
+	metaDescription string
+}
+
+// ParseJobPosting extracts a job posting from the HTML of a job page. The
+// schema.org JobPosting JSON-LD most job boards embed is used when present;
+// otherwise the title and company are guessed from the page title, the
+// first heading and the Open Graph tags.
+func ParseJobPosting(page string) (models.SavedJob, error) {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return models.SavedJob{}, err
+	}
+	info := pageInfo{}
+	collectPageInfo(doc, &info)
+
+	for _, block := range info.jsonLD {
+		var data interface{}
+		if json.Unmarshal([]byte(block), &data) != nil {
+			continue
+		}
+		if posting := findJobPosting(data); posting != nil {
+			job := jobFromJSONLD(posting)
+			if job.CompanyName == "" {
+				job.CompanyName = info.siteName
+			}
+			if job.Title != "" {
+				return job, nil
+			}
+		}
+	}
+
+	return jobFromHeuristics(info)
+}
+
+func collectPageInfo(n *html.Node, info *pageInfo) {
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.Script:
+			if strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") && n.FirstChild != nil {
+				info.jsonLD = append(info.jsonLD, n.FirstChild.Data)
+			}
+			return
+		case atom.Style:
+			return
+		case atom.Title:
+			if info.title == "" {
+				info.title = collapseSpace(nodeText(n))
+			}
+		case atom.H1:
+			if info.heading == "" {
+				info.heading = collapseSpace(nodeText(n))
+			}
+		case atom.Meta:
+			content := strings.TrimSpace(attr(n, "content"))
+			switch strings.ToLower(attr(n, "property") + attr(n, "name")) {
+			case "og:title":
+				info.ogTitle = content
+			case "og:site_name":
+				info.siteName = content
+			case "description", "og:description":
+				if info.metaDescription == "" {
+					info.metaDescription = content
+				}
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		collectPageInfo(child, info)
+	}
+}
+
+// findJobPosting looks for a JobPosting node in a JSON-LD document, which
+// may be a single object, a list of objects or an object with an @graph.
+func findJobPosting(data interface{}) map[string]interface{} {
+	switch value := data.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if posting := findJobPosting(item); posting != nil {
+				return posting
+			}
+		}
+	case map[string]interface{}:
+		if hasType(value["@type"], "JobPosting") {
+			return value
+		}
+		if graph, ok := value["@graph"]; ok {
+			return findJobPosting(graph)
+		}
+	}
+	return nil
+}
+
+func hasType(value interface{}, name string) bool {
+	switch t := value.(type) {
+	case string:
+		return t == name || strings.HasSuffix(t, "/"+name)
+	case []interface{}:
+		for _, item := range t {
+			if hasType(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jobFromJSONLD(posting map[string]interface{}) models.SavedJob {
+	job := models.SavedJob{
+		Title:          collapseSpace(html.UnescapeString(ldString(posting["title"]))),
+		CompanyName:    collapseSpace(ldString(posting["hiringOrganization"])),
+		EmploymentType: strings.ToLower(strings.Join(ldStrings(posting["employmentType"]), ", ")),
+		Description:    HTMLToText(ldString(posting["description"])),
+		DatePosted:     ldDate(posting["datePosted"]),
+		ValidThrough:   ldDate(posting["validThrough"]),
+		Remote:         strings.EqualFold(ldString(posting["jobLocationType"]), "TELECOMMUTE"),
+		Source:         models.JSONLDJobSource,
+	}
+	job.Location = ldLocation(posting["jobLocation"])
+	if job.Location == "" && job.Remote {
+		job.Location = "Remote"
+	}
+	job.Salary = ldSalary(posting["baseSalary"])
+	return job
+}
+
+// ldString reads a text value. Objects such as an Organization are read
+// through their name; lists yield their first entry.
+func ldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		for _, item := range v {
+			if s := ldString(item); s != "" {
+				return s
+			}
+		}
+	case map[string]interface{}:
+		if name, ok := v["name"]; ok {
+			return ldString(name)
+		}
+		if text, ok := v["@value"]; ok {
+			return ldString(text)
+		}
+	}
+	return ""
+}
+
+func ldStrings(value interface{}) []string {
+	values := []string{}
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if s := ldString(item); s != "" {
+				values = append(values, s)
+			}
+		}
+	} else if s := ldString(value); s != "" {
+		values = append(values, s)
+	}
+	return values
+}
+
+func ldNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func ldDate(value interface{}) *time.Time {
+	text := ldString(value)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// ldLocation formats one or more Place nodes as "City, Region, Country".
+func ldLocation(value interface{}) string {
+	places := []interface{}{value}
+	if list, ok := value.([]interface{}); ok {
+		places = list
+	}
+
+	locations := []string{}
+	for _, place := range places {
+		node, ok := place.(map[string]interface{})
+		if !ok {
+			if s := ldString(place); s != "" {
+				locations = append(locations, s)
+			}
+			continue
+		}
+		address, ok := node["address"].(map[string]interface{})
+		if !ok {
+			if s := ldString(node["address"]); s != "" {
+				locations = append(locations, s)
+			} else if s := ldString(node); s != "" {
+				locations = append(locations, s)
+			}
+			continue
+		}
+		location := joinNonEmpty(", ",
+			ldString(address["addressLocality"]),
+			ldString(address["addressRegion"]),
+			ldString(address["addressCountry"]),
+		)
+		if location != "" {
+			locations = append(locations, location)
+		}
+	}
+	return strings.Join(locations, "; ")
+}
+
+// ldSalary reads a MonetaryAmount. Salaries without a currency or with a
+// period SalaryRange cannot express are dropped rather than guessed.
+func ldSalary(value interface{}) *models.SalaryRange {
+	amount, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	currency := strings.ToUpper(ldString(amount["currency"]))
+	if len(currency) != 3 {
+		return nil
+	}
+
+	salary := models.SalaryRange{Currency: currency}
+	unit := ""
+	switch quantity := amount["value"].(type) {
+	case map[string]interface{}:
+		unit = ldString(quantity["unitText"])
+		if n, ok := ldNumber(quantity["value"]); ok {
+			salary.Min, salary.Max = n, n
+		}
+		if n, ok := ldNumber(quantity["minValue"]); ok {
+			salary.Min = n
+		}
+		if n, ok := ldNumber(quantity["maxValue"]); ok {
+			salary.Max = n
+		}
+	default:
+		if n, ok := ldNumber(quantity); ok {
+			salary.Min, salary.Max = n, n
+		}
+	}
+	if unit == "" {
+		unit = ldString(amount["unitText"])
+	}
+
+	period, ok := salaryPeriods[strings.ToUpper(unit)]
+	if !ok || salary.Max == 0 {
+		return nil
+	}
+	salary.Period = period
+	if salary.Min > salary.Max {
+		salary.Min = salary.Max
+	}
+	return &salary
+}
+
+// jobFromHeuristics guesses the title and company from a page without
+// JobPosting markup, e.g. "Backend Engineer at Acme" or "Backend Engineer -
+// Acme | Careers".
+func jobFromHeuristics(info pageInfo) (models.SavedJob, error) {
+	candidates := []string{}
+	for _, text := range []string{info.heading, info.ogTitle, info.title} {
+		if text != "" {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return models.SavedJob{}, ErrNoJobPosting
+	}
+
+	title := candidates[0]
+	company := info.siteName
+	if role, named, ok := splitTitleCompany(title); ok {
+		title = role
+		if company == "" {
+			company = named
+		}
+	}
+	// A bare heading often leaves the company to the page title.
+	for _, text := range candidates[1:] {
+		if company != "" {
+			break
+		}
+		if _, named, ok := splitTitleCompany(text); ok {
+			company = named
+		}
+	}
+
+	return models.SavedJob{
+		Title:       title,
+		CompanyName: company,
+		Description: info.metaDescription,
+		Source:      models.HeuristicJobSource,
+	}, nil
+}
+
+// splitTitleCompany splits "Role at Company" and the dash or pipe
+// separated variants job boards use in page titles.
+func splitTitleCompany(text string) (string, string, bool) {
+	for _, separator := range []string{" at ", " @ ", " - ", " – ", " | "} {
+		parts := strings.SplitN(text, separator, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		company := parts[1]
+		for _, trailer := range []string{" | ", " - ", " – "} {
+			company = strings.SplitN(company, trailer, 2)[0]
+		}
+		role, company := strings.TrimSpace(parts[0]), strings.TrimSpace(company)
+		if role != "" && company != "" {
+			return role, company, true
+		}
+	}
+	return "", "", false
+}
+
+// HTMLToText turns an HTML fragment into plain text, keeping paragraphs
+// and list items on their own lines. Descriptions that arrive with their
+// markup escaped are unescaped first.
+func HTMLToText(fragment string) string {
+	if !strings.Contains(fragment, "<") && strings.Contains(fragment, "&lt;") {
+		fragment = html.UnescapeString(fragment)
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return strings.TrimSpace(fragment)
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		writeNodeText(&b, node)
+	}
+
+	lines := []string{}
+	blank := false
+	for _, line := range strings.Split(b.String(), "\n") {
+		line = collapseSpace(line)
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeNodeText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Script, atom.Style:
+			return
+		case atom.Br:
+			b.WriteString("\n")
+			return
+		case atom.Li:
+			b.WriteString("\n- ")
+		case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Ul, atom.Ol:
+			b.WriteString("\n\n")
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeNodeText(b, child)
+	}
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Ul, atom.Ol:
+			b.WriteString("\n\n")
+		}
+	}
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	writeNodeText(&b, n)
+	return b.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateResumeLayout checks that a layout only refers to sections the
+// resume can have and to entries those sections hold.
+func ValidateResumeLayout(layout models.ResumeLayout, resume models.Resume) error {
+	known := map[string][]string{}
+	for _, section := range resumeSections {
+		known[section.key] = sectionEntryIDs(resume, section.key)
+	}
+	for _, section := range resume.CustomSections {
+		known[section.ID.Hex()] = entryIDs(section.Entries, customEntryID)
+	}
+
+	seen := map[string]bool{}
+	for _, key := range layout.SectionOrder {
+		if _, ok := known[key]; !ok {
+			return fmt.Errorf("unknown section %q in section_order", key)
+		}
+		if seen[key] {
+			return fmt.Errorf("section %q is listed twice in section_order", key)
+		}
+		seen[key] = true
+	}
+	for _, key := range layout.HiddenSections {
+		if _, ok := known[key]; !ok {
+			return fmt.Errorf("unknown section %q in hidden_sections", key)
+		}
+	}
+	for key, ids := range layout.HiddenEntries {
+		entries, ok := known[key]
+		if !ok {
+			return fmt.Errorf("unknown section %q in hidden_entries", key)
+		}
+		for _, id := range ids {
+			if !slices.Contains(entries, id) {
+				return fmt.Errorf("entry %q of section %q does not exist", id, key)
+			}
+		}
+	}
+	return nil
+}
+
+// ResumeSectionOrder returns the keys of all sections in render order: the
+// layout's order first, then the fixed sections in their default order and
+// finally the custom sections by position.
+func ResumeSectionOrder(resume models.Resume) []string {
+	var defaults []string
+	for _, section := range resumeSections {
+		defaults = append(defaults, section.key)
+	}
+	for _, section := range SortedCustomSections(resume.CustomSections) {
+		defaults = append(defaults, section.ID.Hex())
+	}
+	if resume.Layout == nil {
+		return defaults
+	}
+
+	available := map[string]bool{}
+	for _, key := range defaults {
+		available[key] = true
+	}
+
+	var order []string
+	for _, key := range resume.Layout.SectionOrder {
+		if available[key] {
+			order = append(order, key)
+			delete(available, key)
+		}
+	}
+	for _, key := range defaults {
+		if available[key] {
+			order = append(order, key)
+		}
+	}
+	return order
+}
+
+// ApplyResumeLayout returns a copy of the resume with hidden sections and
+// hidden entries removed. The stored resume keeps everything, so a tailored
+// variant can bring an entry back by editing its layout.
+func ApplyResumeLayout(resume models.Resume) models.Resume {
+	if resume.Layout == nil {
+		return resume
+	}
+	layout := resume.Layout
+
+	hidden := map[string]bool{}
+	for _, key := range layout.HiddenSections {
+		hidden[key] = true
+	}
+
+	if hidden["summary"] {
+		resume.Summary = nil
+	}
+	resume.Skills = visibleEntries(resume.Skills, hidden["skills"], layout.HiddenEntries["skills"], textID)
+	resume.WorkExperience = visibleEntries(resume.WorkExperience, hidden["work_experience"], layout.HiddenEntries["work_experience"], workID)
+	resume.Education = visibleEntries(resume.Education, hidden["education"], layout.HiddenEntries["education"], educationID)
+	resume.Projects = visibleEntries(resume.Projects, hidden["projects"], layout.HiddenEntries["projects"], projectID)
+	resume.Certifications = visibleEntries(resume.Certifications, hidden["certifications"], layout.HiddenEntries["certifications"], certificationID)
+	resume.Languages = visibleEntries(resume.Languages, hidden["languages"], layout.HiddenEntries["languages"], textID)
+	resume.HonorsAwards = visibleEntries(resume.HonorsAwards, hidden["honors_awards"], layout.HiddenEntries["honors_awards"], honorID)
+	resume.Extracurriculars = visibleEntries(resume.Extracurriculars, hidden["extracurriculars"], layout.HiddenEntries["extracurriculars"], extracurricularID)
+
+	var custom []models.CustomSection
+	for _, section := range resume.CustomSections {
+		key := section.ID.Hex()
+		if hidden[key] {
+			continue
+		}
+		section.Entries = visibleEntries(section.Entries, false, layout.HiddenEntries[key], customEntryID)
+		custom = append(custom, section)
+	}
+	resume.CustomSections = custom
+
+	return resume
+}
+
+// visibleEntries drops the entries whose ID is hidden. IDs that no longer
+// match an entry are ignored, so deleting an entry cannot hide another one.
+func visibleEntries[T any](entries []T, hideAll bool, hiddenIDs []string, id func(T) string) []T {
+	if hideAll {
+		return nil
+	}
+	if len(hiddenIDs) == 0 {
+		return entries
+	}
+
+	skip := map[string]bool{}
+	for _, hiddenID := range hiddenIDs {
+		skip[hiddenID] = true
+	}
+	visible := []T{}
+	for _, entry := range entries {
+		if !skip[id(entry)] {
+			visible = append(visible, entry)
+		}
+	}
+	return visible
+}
+
+func entryIDs[T any](entries []T, id func(T) string) []string {
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, id(entry))
+	}
+	return ids
+}
+
+func sectionEntryIDs(resume models.Resume, key string) []string {
+	switch key {
+	case "skills":
+		return entryIDs(resume.Skills, textID)
+	case "work_experience":
+		return entryIDs(resume.WorkExperience, workID)
+	case "education":
+		return entryIDs(resume.Education, educationID)
+	case "projects":
+		return entryIDs(resume.Projects, projectID)
+	case "certifications":
+		return entryIDs(resume.Certifications, certificationID)
+	case "languages":
+		return entryIDs(resume.Languages, textID)
+	case "honors_awards":
+		return entryIDs(resume.HonorsAwards, honorID)
+	case "extracurriculars":
+		return entryIDs(resume.Extracurriculars, extracurricularID)
+	}
+	return nil
+}
+
+func textID(text string) string                             { return text }
+func workID(entry models.WorkExperience) string             { return entry.ID.Hex() }
+func educationID(entry models.Education) string             { return entry.ID.Hex() }
+func projectID(entry models.Project) string                 { return entry.ID.Hex() }
+func certificationID(entry models.Certification) string     { return entry.ID.Hex() }
+func honorID(entry models.HonorAward) string                { return entry.ID.Hex() }
+func extracurricularID(entry models.Extracurricular) string { return entry.ID.Hex() }
+func customEntryID(entry models.CustomEntry) string         { return entry.ID.Hex() }
+
+// AssignEntryIDs gives every resume entry that has no ID yet a new one, so
+// layouts can refer to it. It reports whether any entry changed.
+func AssignEntryIDs(resume *models.Resume) bool {
+	changed := false
+	assign := func(id *primitive.ObjectID) {
+		if id.IsZero() {
+			*id = primitive.NewObjectID()
+			changed = true
+		}
+	}
+
+	for i := range resume.WorkExperience {
+		assign(&resume.WorkExperience[i].ID)
+	}
+	for i := range resume.Education {
+		assign(&resume.Education[i].ID)
+	}
+	for i := range resume.Projects {
+		assign(&resume.Projects[i].ID)
+	}
+	for i := range resume.Certifications {
+		assign(&resume.Certifications[i].ID)
+	}
+	for i := range resume.HonorsAwards {
+		assign(&resume.HonorsAwards[i].ID)
+	}
+	for i := range resume.Extracurriculars {
+		assign(&resume.Extracurriculars[i].ID)
+	}
+	for i := range resume.CustomSections {
+		if AssignCustomEntryIDs(&resume.CustomSections[i]) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// AssignCustomEntryIDs gives every entry of a custom section that has no ID
+// yet a new one. It reports whether any entry changed.
+func AssignCustomEntryIDs(section *models.CustomSection) bool {
+	changed := false
+	for i := range section.Entries {
+		if section.Entries[i].ID.IsZero() {
+			section.Entries[i].ID = primitive.NewObjectID()
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type resumeLocale struct {
+	months   [12]string
+	present  string
+	headings map[string]string
+}
+
+var resumeLocales = map[string]resumeLocale{
+	"en": {
+		months:  [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		present: "Present",
+		headings: map[string]string{
+			"summary":          "Summary",
+			"skills":           "Skills",
+			"work_experience":  "Work Experience",
+			"education":        "Education",
+			"projects":         "Projects",
+			"certifications":   "Certifications",
+			"languages":        "Languages",
+			"honors_awards":    "Honors & Awards",
+			"extracurriculars": "Extracurriculars",
+		},
+	},
+	"de": {
+		months:  [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		present: "heute",
+		headings: map[string]string{
+			"summary":          "Profil",
+			"skills":           "Kenntnisse",
+			"work_experience":  "Berufserfahrung",
+			"education":        "Ausbildung",
+			"projects":         "Projekte",
+			"certifications":   "Zertifikate",
+			"languages":        "Sprachen",
+			"honors_awards":    "Auszeichnungen",
+			"extracurriculars": "Engagement",
+		},
+	},
+	"fr": {
+		months:  [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		present: "aujourd'hui",
+		headings: map[string]string{
+			"summary":          "Profil",
+			"skills":           "Compétences",
+			"work_experience":  "Expérience professionnelle",
+			"education":        "Formation",
+			"projects":         "Projets",
+			"certifications":   "Certifications",
+			"languages":        "Langues",
+			"honors_awards":    "Distinctions",
+			"extracurriculars": "Activités extra-professionnelles",
+		},
+	},
+	"es": {
+		months:  [12]string{"ene.", "feb.", "mar.", "abr.", "may.", "jun.", "jul.", "ago.", "sept.", "oct.", "nov.", "dic."},
+		present: "actualidad",
+		headings: map[string]string{
+			"summary":          "Perfil",
+			"skills":           "Habilidades",
+			"work_experience":  "Experiencia laboral",
+			"education":        "Formación",
+			"projects":         "Proyectos",
+			"certifications":   "Certificaciones",
+			"languages":        "Idiomas",
+			"honors_awards":    "Premios",
+			"extracurriculars": "Actividades extracurriculares",
+		},
+	},
+}
+
+// localeFor returns the formatting rules for a resume locale, falling back
+// to English for resumes created before locales existed.
+func localeFor(locale string) resumeLocale {
+	if rules, ok := resumeLocales[locale]; ok {
+		return rules
+	}
+	return resumeLocales[models.DefaultLocale]
+}
+
+func (l resumeLocale) heading(key string, fallback string) string {
+	if heading, ok := l.headings[key]; ok {
+		return heading
+	}
+	return fallback
+}
+
+func (l resumeLocale) formatMonth(t time.Time) string {
+	return fmt.Sprintf("%s %d", l.months[t.Month()-1], t.Year())
+}
+
+func (l resumeLocale) formatDateRange(start time.Time, end time.Time, ongoing bool) string {
+	if start.IsZero() && end.IsZero() {
+		return ""
+	}
+	to := ""
+	if ongoing {
+		to = l.present
+	} else if !end.IsZero() {
+		to = l.formatMonth(end)
+	}
+	if start.IsZero() {
+		return to
+	}
+	if to == "" {
+		return l.formatMonth(start)
+	}
+	return l.formatMonth(start) + " - " + to
+}
+
+// CheckLocaleSync compares two translations of the same resume and reports
+// entries that exist in one but not the other. Entries are matched on the
+// parts that do not get translated: company names, dates and links.
+func CheckLocaleSync(a models.Resume, b models.Resume) []models.LocaleSyncIssue {
+	issues := []models.LocaleSyncIssue{}
+
+	compare := func(section string, keysA map[string]int, keysB map[string]int) {
+		for key, countA := range keysA {
+			countB, ok := keysB[key]
+			if !ok {
+				issues = append(issues, models.LocaleSyncIssue{Section: section, Entry: key, PresentIn: a.Locale, MissingIn: b.Locale})
+			} else if countA != countB {
+				issues = append(issues, models.LocaleSyncIssue{
+					Section:   section,
+					Entry:     key,
+					PresentIn: a.Locale,
+					MissingIn: b.Locale,
+					Detail:    fmt.Sprintf("%d items in %s, %d in %s", countA, a.Locale, countB, b.Locale),
+				})
+			}
+		}
+		for key := range keysB {
+			if _, ok := keysA[key]; !ok {
+				issues = append(issues, models.LocaleSyncIssue{Section: section, Entry: key, PresentIn: b.Locale, MissingIn: a.Locale})
+			}
+		}
+	}
+
+	compare("work_experience", workExperienceKeys(a), workExperienceKeys(b))
+	compare("education", educationKeys(a), educationKeys(b))
+	compare("projects", projectKeys(a), projectKeys(b))
+	compare("certifications", certificationKeys(a), certificationKeys(b))
+	compare("custom_sections", customSectionKeys(a), customSectionKeys(b))
+
+	for _, section := range []struct {
+		name   string
+		countA int
+		countB int
+	}{
+		{"skills", len(a.Skills), len(b.Skills)},
+		{"languages", len(a.Languages), len(b.Languages)},
+		{"honors_awards", len(a.HonorsAwards), len(b.HonorsAwards)},
+		{"extracurriculars", len(a.Extracurriculars), len(b.Extracurriculars)},
+	} {
+		if section.countA != section.countB {
+			issues = append(issues, models.LocaleSyncIssue{
+				Section:   section.name,
+				PresentIn: a.Locale,
+				MissingIn: b.Locale,
+				Detail:    fmt.Sprintf("%d entries in %s, %d in %s", section.countA, a.Locale, section.countB, b.Locale),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Section != issues[j].Section {
+			return issues[i].Section < issues[j].Section
+		}
+		return issues[i].Entry < issues[j].Entry
+	})
+	return issues
+}
+
+func workExperienceKeys(resume models.Resume) map[string]int {
+	keys := map[string]int{}
+	for i, work := range resume.WorkExperience {
+		addSyncKey(keys, syncKey(work.CompanyName, work.StartDate), i, len(work.BulletPoints))
+	}
+	return keys
+}
+
+func educationKeys(resume models.Resume) map[string]int {
+	keys := map[string]int{}
+	for i, education := range resume.Education {
+		key := syncKey("", education.StartDate)
+		if !education.EndDate.IsZero() {
+			key = strings.TrimSpace(key + " - " + education.EndDate.Format("2006-01"))
+		}
+		addSyncKey(keys, key, i, 0)
+	}
+	return keys
+}
+
+func projectKeys(resume models.Resume) map[string]int {
+	keys := map[string]int{}
+	for i, project := range resume.Projects {
+		key := syncKey("", project.StartDate)
+		if project.ProjectUrl != nil && *project.ProjectUrl != "" {
+			key = *project.ProjectUrl
+		}
+		addSyncKey(keys, key, i, len(project.BulletPoints))
+	}
+	return keys
+}
+
+func certificationKeys(resume models.Resume) map[string]int {
+	keys := map[string]int{}
+	for i, certification := range resume.Certifications {
+		key := certification.CertificateLink
+		if key == "" {
+			key = strings.ToLower(certification.Title)
+		}
+		addSyncKey(keys, key, i, 0)
+	}
+	return keys
+}
+
+// addSyncKey records an entry under its key. An entry with nothing to key
+// on falls back to its position, and entries sharing a key are numbered in
+// order, so neither collapses into the entry before it.
+func addSyncKey(keys map[string]int, key string, position int, count int) {
+	if key == "" {
+		key = fmt.Sprintf("entry %d", position+1)
+	}
+	if _, taken := keys[key]; taken {
+		for n := 2; ; n++ {
+			if _, taken := keys[fmt.Sprintf("%s #%d", key, n)]; !taken {
+				key = fmt.Sprintf("%s #%d", key, n)
+				break
+			}
+		}
+	}
+	keys[key] = count
+}
+
+// customSectionKeys relies on translations being created as copies, so the
+// same custom section keeps its ID across locales.
+func customSectionKeys(resume models.Resume) map[string]int {
+	keys := map[string]int{}
+	for _, section := range resume.CustomSections {
+		keys[section.ID.Hex()] = len(section.Entries)
+	}
+	return keys
+}
+
+func syncKey(name string, start time.Time) string {
+	if start.IsZero() {
+		return strings.ToLower(name)
+	}
+	return strings.TrimSpace(strings.ToLower(name) + " " + start.Format("2006-01"))
+}
@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ComparisonYears is how many years the offer comparison looks ahead. Most
+// grants vest over four years.
+const ComparisonYears = 4
+
+const defaultHoursPerWeek = 40
+
+// ValidateOffer checks what the struct tags cannot: that every equity grant
+// can be valued and that custom vesting schedules add up. Currency codes
+// are stored upper case.
+func ValidateOffer(offer *models.Offer) error {
+	offer.Currency = strings.ToUpper(offer.Currency)
+	for i := range offer.Equity {
+		grant := &offer.Equity[i]
+		grant.Currency = strings.ToUpper(grant.Currency)
+
+		switch {
+		case grant.Type == models.OptionEquity && (grant.Shares == 0 || grant.PricePerShare == 0):
+			return fmt.Errorf("equity grant %d: options need shares and price_per_share", i+1)
+		case grant.Shares > 0 && grant.PricePerShare == 0:
+			return fmt.Errorf("equity grant %d: price_per_share is required with shares", i+1)
+		case grant.Shares == 0 && grant.GrantValue == 0:
+			return fmt.Errorf("equity grant %d: either shares and price_per_share or grant_value is required", i+1)
+		}
+
+		if len(grant.YearlyPercent) > 0 {
+			if len(grant.YearlyPercent) != grant.VestingYears {
+				return fmt.Errorf("equity grant %d: yearly_percent needs %d entries", i+1, grant.VestingYears)
+			}
+			total := 0.0
+			for _, percent := range grant.YearlyPercent {
+				total += percent
+			}
+			if math.Abs(total-100) > 0.01 {
+				return fmt.Errorf("equity grant %d: yearly_percent adds up to %g, not 100", i+1, total)
+			}
+		}
+	}
+	return nil
+}
+
+// VestingByYear returns the share of a grant that vests in each of the
+// first years. Without a custom schedule the grant vests monthly and
+// evenly, with everything up to the cliff vesting at the cliff.
+func VestingByYear(grant models.EquityGrant, years int) []float64 {
+	shares := make([]float64, years)
+	if len(grant.YearlyPercent) > 0 {
+		for i := 0; i < years && i < len(grant.YearlyPercent); i++ {
+			shares[i] = grant.YearlyPercent[i] / 100
+		}
+		return shares
+	}
+
+	months := float64(12 * grant.VestingYears)
+	vested := func(month int) float64 {
+		if month < grant.CliffMonths {
+			return 0
+		}
+		return math.Min(float64(month), months) / months
+	}
+	for i := range shares {
+		shares[i] = vested(12*(i+1)) - vested(12*i)
+	}
+	return shares
+}
+
+// grantValue values a grant at today's share price. Options are worth only
+// their spread over the strike price.
+func grantValue(grant models.EquityGrant) float64 {
+	if grant.Shares == 0 {
+		return grant.GrantValue
+	}
+	if grant.Type == models.OptionEquity {
+		return grant.Shares * math.Max(0, grant.PricePerShare-grant.StrikePrice)
+	}
+	return grant.Shares * grant.PricePerShare
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// breakdownBuilder sums the components of a breakdown before rounding, so
+// the total does not pick up the rounding of each component.
+type breakdownBuilder struct {
+	models.CompensationBreakdown
+	total float64
+}
+
+func (b *breakdownBuilder) add(component *float64, amount float64) {
+	*component += amount
+	b.total += amount
+}
+
+func (b breakdownBuilder) result() models.CompensationBreakdown {
+	return models.CompensationBreakdown{
+		Base:     roundMoney(b.Base),
+		Bonus:    roundMoney(b.Bonus),
+		SignOn:   roundMoney(b.SignOn),
+		Equity:   roundMoney(b.Equity),
+		Benefits: roundMoney(b.Benefits),
+		Total:    roundMoney(b.total),
+	}
+}
+
+// SummarizeOffer normalises an application's offer to the comparison
+// currency. rates maps upper case currency codes to the value of one unit
+// in that currency.
+func SummarizeOffer(application models.JobApplication, currency string, rates map[string]float64) (models.OfferSummary, error) {
+	offer := application.Offer
+	summary := models.OfferSummary{
+		ApplicationID: application.ID,
+		CompanyName:   application.CompanyName,
+		RoleTitle:     application.RoleTitle,
+		Location:      offer.Location,
+		Remote:        offer.Remote,
+		Notes:         []string{},
+	}
+	if summary.Location == "" {
+		summary.Location = application.Location
+	}
+
+	convert := func(amount float64, code string) (float64, error) {
+		code = strings.ToUpper(code)
+		if code == "" || code == currency {
+			return amount, nil
+		}
+		rate, ok := rates[code]
+		if !ok {
+			return 0, fmt.Errorf("%s: no exchange rate for %s", application.CompanyName, code)
+		}
+		return amount * rate, nil
+	}
+
+	base, err := convert(offer.BaseSalary, offer.Currency)
+	if err != nil {
+		return summary, err
+	}
+	bonus, err := convert(offer.BonusAmount+offer.BaseSalary*offer.BonusPercent/100, offer.Currency)
+	if err != nil {
+		return summary, err
+	}
+	signOn, err := convert(offer.SignOnBonus, offer.Currency)
+	if err != nil {
+		return summary, err
+	}
+	benefits := 0.0
+	for _, benefit := range offer.Benefits {
+		value, err := convert(benefit.AnnualValue, offer.Currency)
+		if err != nil {
+			return summary, err
+		}
+		benefits += value
+	}
+
+	equity := make([]float64, ComparisonYears)
+	for i, grant := range offer.Equity {
+		code := grant.Currency
+		if code == "" {
+			code = offer.Currency
+		}
+		value, err := convert(grantValue(grant), code)
+		if err != nil {
+			return summary, err
+		}
+
+		vesting := VestingByYear(grant, ComparisonYears)
+		counted := 0.0
+		for year, share := range vesting {
+			equity[year] += value * share
+			counted += share
+		}
+
+		if grant.Type == models.OptionEquity {
+			if grant.PricePerShare <= grant.StrikePrice {
+				summary.Notes = append(summary.Notes, fmt.Sprintf("equity grant %d: options are under water and counted as worth nothing", i+1))
+			} else {
+				summary.Notes = append(summary.Notes, fmt.Sprintf("equity grant %d: options are counted at their spread over the strike price, before tax and exercise cost", i+1))
+			}
+		}
+		if len(grant.YearlyPercent) == 0 && grant.CliffMonths > 12 {
+			summary.Notes = append(summary.Notes, fmt.Sprintf("equity grant %d: nothing vests in year one because of the %d-month cliff", i+1, grant.CliffMonths))
+		}
+		if counted < 0.9999 {
+			summary.Notes = append(summary.Notes, fmt.Sprintf("equity grant %d: %.0f%% vests after year %d and is not counted", i+1, (1-counted)*100, ComparisonYears))
+		}
+	}
+	if offer.BonusPercent > 0 {
+		summary.Notes = append(summary.Notes, "the bonus is the target bonus; the actual payout may differ")
+	}
+	if signOn > 0 {
+		summary.Notes = append(summary.Notes, "the sign-on bonus is paid once, in year one")
+	}
+
+	var year1, average breakdownBuilder
+	summary.YearlyTotals = []float64{}
+	for year := 0; year < ComparisonYears; year++ {
+		total := base + bonus + benefits + equity[year]
+		if year == 0 {
+			total += signOn
+		}
+		summary.YearlyTotals = append(summary.YearlyTotals, roundMoney(total))
+	}
+	year1.add(&year1.Base, base)
+	year1.add(&year1.Bonus, bonus)
+	year1.add(&year1.SignOn, signOn)
+	year1.add(&year1.Equity, equity[0])
+	year1.add(&year1.Benefits, benefits)
+
+	vested := 0.0
+	for _, amount := range equity {
+		vested += amount
+	}
+	average.add(&average.Base, base)
+	average.add(&average.Bonus, bonus)
+	average.add(&average.SignOn, signOn/ComparisonYears)
+	average.add(&average.Equity, vested/ComparisonYears)
+	average.add(&average.Benefits, benefits)
+
+	summary.Year1 = year1.result()
+	summary.FourYearAverage = average.result()
+
+	hoursPerWeek := offer.HoursPerWeek
+	if hoursPerWeek == 0 {
+		hoursPerWeek = defaultHoursPerWeek
+	}
+	hoursPerYear := hoursPerWeek * 52
+	summary.Year1PerHour = roundMoney(year1.total / hoursPerYear)
+	summary.FourYearAveragePerHour = roundMoney(average.total / hoursPerYear)
+	return summary, nil
+}
+
+// CompareOffers summarises every application's offer in one currency,
+// best four-year average first.
+func CompareOffers(applications []models.JobApplication, currency string, rates map[string]float64) (models.OfferComparison, error) {
+	currency = strings.ToUpper(currency)
+	normalized := map[string]float64{}
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+
+	comparison := models.OfferComparison{Currency: currency, Offers: []models.OfferSummary{}}
+	for _, application := range applications {
+		if application.Offer == nil {
+			continue
+		}
+		summary, err := SummarizeOffer(application, currency, normalized)
+		if err != nil {
+			return comparison, err
+		}
+		comparison.Offers = append(comparison.Offers, summary)
+	}
+	sort.SliceStable(comparison.Offers, func(i, j int) bool {
+		return comparison.Offers[i].FourYearAverage.Total > comparison.Offers[j].FourYearAverage.Total
+	})
+	return comparison, nil
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// RedactedText replaces contact details removed from free text.
+const RedactedText = "[redacted]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d ().-]{6,}\d`)
+)
+
+// RedactResume returns a copy of the resume with the fields hidden by the
+// given profile cleared. Hidden email addresses and phone numbers are also
+// replaced in the free text of every section, since a summary or bullet
+// point can repeat them. The original resume is left untouched.
+func RedactResume(resume models.Resume, profile models.RedactionProfile) (models.Resume, error) {
+	rules, ok := models.RedactionProfiles[profile]
+	if !ok {
+		return resume, fmt.Errorf("unknown redaction profile %q", profile)
+	}
+
+	original := resume
+	text := func(s string) string {
+		return redactText(s, rules, &original)
+	}
+
+	if rules.HidePhoneNumber {
+		resume.PhoneNumber = ""
+	}
+	if rules.HideEmail {
+		resume.Email = ""
+	}
+	resume.Location = RedactLocation(resume.Location, rules.Location)
+	resume.Summary = redactTextPointer(resume.Summary, text)
+
+	if len(resume.Education) > 0 {
+		education := make([]models.Education, len(resume.Education))
+		copy(education, resume.Education)
+		for i := range education {
+			education[i].Location = RedactLocation(education[i].Location, rules.Location)
+			if rules.HideGPA {
+				education[i].GPA = 0
+			}
+		}
+		resume.Education = education
+	}
+
+	if len(resume.WorkExperience) > 0 {
+		work := make([]models.WorkExperience, len(resume.WorkExperience))
+		copy(work, resume.WorkExperience)
+		for i := range work {
+			work[i].Location = RedactLocation(work[i].Location, rules.Location)
+			work[i].BulletPoints = redactLines(work[i].BulletPoints, text)
+		}
+		resume.WorkExperience = work
+	}
+
+	if len(resume.Projects) > 0 {
+		projects := make([]models.Project, len(resume.Projects))
+		copy(projects, resume.Projects)
+		for i := range projects {
+			projects[i].Description = redactTextPointer(projects[i].Description, text)
+			projects[i].BulletPoints = redactLines(projects[i].BulletPoints, text)
+		}
+		resume.Projects = projects
+	}
+
+	if len(resume.Certifications) > 0 {
+		certifications := make([]models.Certification, len(resume.Certifications))
+		copy(certifications, resume.Certifications)
+		for i := range certifications {
+			certifications[i].Description = text(certifications[i].Description)
+		}
+		resume.Certifications = certifications
+	}
+
+	if len(resume.HonorsAwards) > 0 {
+		honors := make([]models.HonorAward, len(resume.HonorsAwards))
+		copy(honors, resume.HonorsAwards)
+		for i := range honors {
+			honors[i].Description = text(honors[i].Description)
+		}
+		resume.HonorsAwards = honors
+	}
+
+	if len(resume.Extracurriculars) > 0 {
+		activities := make([]models.Extracurricular, len(resume.Extracurriculars))
+		copy(activities, resume.Extracurriculars)
+		for i := range activities {
+			activities[i].Description = text(activities[i].Description)
+		}
+		resume.Extracurriculars = activities
+	}
+
+	if len(resume.CustomSections) > 0 {
+		sections := make([]models.CustomSection, len(resume.CustomSections))
+		copy(sections, resume.CustomSections)
+		for i := range sections {
+			entries := make([]models.CustomEntry, len(sections[i].Entries))
+			copy(entries, sections[i].Entries)
+			for j := range entries {
+				entries[j].Text = text(entries[j].Text)
+				entries[j].Subtitle = text(entries[j].Subtitle)
+				entries[j].Description = text(entries[j].Description)
+				entries[j].Value = text(entries[j].Value)
+			}
+			sections[i].Entries = entries
+		}
+		resume.CustomSections = sections
+	}
+
+	return resume, nil
+}
+
+// RedactCoverLetter applies a redaction profile to the body of a cover
+// letter. Free text has no fields to clear, so hidden email addresses and
+// phone numbers are replaced wherever they appear, and the location of the
+// letter's resume is cut down to the profile's granularity.
+func RedactCoverLetter(letter models.CoverLetter, profile models.RedactionProfile, resume *models.Resume) (models.CoverLetter, error) {
+	rules, ok := models.RedactionProfiles[profile]
+	if !ok {
+		return letter, fmt.Errorf("unknown redaction profile %q", profile)
+	}
+
+	letter.Body = redactText(letter.Body, rules, resume)
+	return letter, nil
+}
+
+// redactText replaces the contact details hidden by the rules wherever they
+// appear in a piece of free text. The resume, when given, supplies the exact
+// phone number and location to look for.
+func redactText(text string, rules models.RedactionRules, resume *models.Resume) string {
+	if text == "" {
+		return text
+	}
+
+	if rules.HideEmail {
+		text = emailPattern.ReplaceAllString(text, RedactedText)
+	}
+	if rules.HidePhoneNumber {
+		if resume != nil && resume.PhoneNumber != "" {
+			text = strings.ReplaceAll(text, resume.PhoneNumber, RedactedText)
+		}
+		// Dates and year ranges also look like digit runs, so only runs
+		// long enough to be a phone number are replaced.
+		text = phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+			digits := 0
+			for _, r := range match {
+				if unicode.IsDigit(r) {
+					digits++
+				}
+			}
+			if digits < 10 {
+				return match
+			}
+			return RedactedText
+		})
+	}
+	if resume != nil && resume.Location != "" {
+		if location := RedactLocation(resume.Location, rules.Location); location != resume.Location {
+			if location == "" {
+				location = RedactedText
+			}
+			text = strings.ReplaceAll(text, resume.Location, location)
+		}
+	}
+	return text
+}
+
+// redactLines redacts every line into a new slice so the caller's slice is
+// left untouched.
+func redactLines(lines []string, redact func(string) string) []string {
+	if lines == nil {
+		return nil
+	}
+	redacted := make([]string, len(lines))
+	for i, line := range lines {
+		redacted[i] = redact(line)
+	}
+	return redacted
+}
+
+func redactTextPointer(text *string, redact func(string) string) *string {
+	if text == nil {
+		return nil
+	}
+	redacted := redact(*text)
+	return &redacted
+}
+
+// RedactLocation trims a comma separated location down to the requested
+// granularity. City keeps the last three parts (city, region, country) and
+// country keeps only the last one.
+func RedactLocation(location string, granularity models.LocationGranularity) string {
+	if location == "" {
+		return location
+	}
+
+	var parts []string
+	for _, part := range strings.Split(location, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	keep := len(parts)
+	switch granularity {
+	case models.LocationHidden:
+		return ""
+	case models.LocationCity:
+		keep = 3
+	case models.LocationCountry:
+		keep = 1
+	}
+	if keep < len(parts) {
+		parts = parts[len(parts)-keep:]
+	}
+	return strings.Join(parts, ", ")
+}
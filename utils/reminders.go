@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// NoResponseAfter is how long after applying a follow-up is suggested.
+	NoResponseAfter = 7 * 24 * time.Hour
+	// ThankYouAfter is how long after moving to interviewing a thank-you
+	// note is due, for applications without scheduled interview rounds.
+	ThankYouAfter = 24 * time.Hour
+	// ThankYouWindow is how long after a scheduled round a thank-you
+	// reminder is still worth sending.
+	ThankYouWindow = 7 * 24 * time.Hour
+	// OfferDeadlineNotice is how long before an offer deadline the user is
+	// reminded.
+	OfferDeadlineNotice = 48 * time.Hour
+	// FollowUpWindow is how long after it came due a no-response or
+	// thank-you follow-up is still created. It lets a worker that was down
+	// catch up, without firing for every old application on first start or
+	// after an import.
+	FollowUpWindow = 72 * time.Hour
+)
+
+// DueReminders returns the reminders an application calls for at the given
+// time. Reminders are only returned once they are due; the caller is
+// expected to drop the ones it already stored by their Key.
+func DueReminders(application models.JobApplication, now time.Time) []models.Reminder {
+	reminders := []models.Reminder{}
+	add := func(kind models.ReminderKind, subject primitive.ObjectID, event time.Time, due time.Time, message string) {
+		if due.After(now) {
+			return
+		}
+		reminders = append(reminders, models.Reminder{
+			UserID:        application.UserID,
+			ApplicationID: application.ID,
+			Kind:          kind,
+			Key:           fmt.Sprintf("%s:%s:%d", kind, subject.Hex(), event.Unix()),
+			Message:       message,
+			DueAt:         due,
+		})
+	}
+
+	recent := func(due time.Time) bool {
+		return !due.Before(now.Add(-FollowUpWindow))
+	}
+
+	switch application.Status {
+	case models.StatusApplied:
+		if application.AppliedAt != nil && recent(application.AppliedAt.Add(NoResponseAfter)) {
+			add(models.ReminderNoResponse, application.ID, *application.AppliedAt, application.AppliedAt.Add(NoResponseAfter),
+				fmt.Sprintf("No response from %s about the %s role yet. Consider sending a follow-up.", application.CompanyName, application.RoleTitle))
+		}
+	case models.StatusInterviewing:
+		if entered, ok := enteredStatus(application); ok && len(application.Interviews) == 0 && recent(entered.Add(ThankYouAfter)) {
+			add(models.ReminderThankYou, application.ID, entered, entered.Add(ThankYouAfter),
+				fmt.Sprintf("Send a thank-you note to %s after your %s interview.", application.CompanyName, application.RoleTitle))
+		}
+	case models.StatusOffer:
+		if application.OfferDeadline != nil && application.OfferDeadline.After(now) {
+			add(models.ReminderOfferDeadline, application.ID, *application.OfferDeadline, application.OfferDeadline.Add(-OfferDeadlineNotice),
+				fmt.Sprintf("The offer from %s for the %s role must be answered by %s.", application.CompanyName, application.RoleTitle, application.OfferDeadline.Format("January 2, 2006 15:04 MST")))
+		}
+	}
+
+	// Scheduled rounds get a thank-you reminder each, as soon as they end.
+	if application.Status != models.StatusWithdrawn {
+		for _, interview := range application.Interviews {
+			end := InterviewEnd(interview)
+			if interview.Format == models.TakeHomeInterview || end.Before(now.Add(-ThankYouWindow)) {
+				continue
+			}
+			add(models.ReminderThankYou, interview.ID, interview.StartsAt, end,
+				fmt.Sprintf("Send a thank-you note to %s after your %s interview for the %s role.", application.CompanyName, interview.Round, application.RoleTitle))
+		}
+	}
+	return reminders
+}
+
+// enteredStatus returns when the application last moved to its current
+// status.
+func enteredStatus(application models.JobApplication) (time.Time, bool) {
+	for i := len(application.StatusHistory) - 1; i >= 0; i-- {
+		if application.StatusHistory[i].To == application.Status {
+			return application.StatusHistory[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
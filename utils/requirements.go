@@ -0,0 +1,663 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// knownSkills lists skills recognised in job descriptions by display name,
+// with the other ways descriptions write them.
+var knownSkills = map[string][]string{
+	"Go":               {"golang"},
+	"Python":           nil,
+	"Java":             nil,
+	"JavaScript":       {"js", "ecmascript"},
+	"TypeScript":       nil,
+	"Node.js":          {"nodejs", "node"},
+	"React":            {"react.js", "reactjs"},
+	"Vue":              {"vue.js", "vuejs"},
+	"Angular":          nil,
+	"C++":              nil,
+	"C#":               nil,
+	"Ruby":             nil,
+	"Ruby on Rails":    {"rails"},
+	"PHP":              nil,
+	"Rust":             nil,
+	"Kotlin":           nil,
+	"Swift":            nil,
+	"Scala":            nil,
+	"SQL":              nil,
+	"PostgreSQL":       {"postgres"},
+	"MySQL":            nil,
+	"MongoDB":          {"mongo"},
+	"Redis":            nil,
+	"Elasticsearch":    nil,
+	"Kafka":            nil,
+	"RabbitMQ":         nil,
+	"GraphQL":          nil,
+	"REST":             {"restful"},
+	"gRPC":             nil,
+	"Docker":           nil,
+	"Kubernetes":       {"k8s"},
+	"Terraform":        nil,
+	"AWS":              {"amazon web services"},
+	"GCP":              {"google cloud"},
+	"Azure":            nil,
+	"Linux":            nil,
+	"Git":              nil,
+	"CI/CD":            {"continuous integration"},
+	"Microservices":    {"microservice"},
+	"Machine Learning": {"ml"},
+	"TensorFlow":       nil,
+	"PyTorch":          nil,
+	"Spark":            {"apache spark"},
+	"Airflow":          nil,
+	"Pandas":           nil,
+	"Tableau":          nil,
+	"Excel":            nil,
+	"Figma":            nil,
+	"HTML":             nil,
+	"CSS":              nil,
+	"Django":           nil,
+	"Flask":            nil,
+	"Spring Boot":      nil,
+	"Agile":            {"scrum"},
+}
+
+// caseSensitiveSkills are skill names that are also everyday words, so
+// they only count when written the way the skill is.
+var caseSensitiveSkills = map[string]bool{
+	"go": true, "rest": true, "swift": true, "spark": true, "excel": true, "r": true, "c": true,
+}
+
+var (
+	mustHeading   = regexp.MustCompile(`(?i)(requirements|qualifications|must[- ]haves?|what you('ll| will)? (need|bring)|you (have|bring|should have)|required|minimum|skills)`)
+	niceHeading   = regexp.MustCompile(`(?i)(nice[- ]to[- ]haves?|preferred|bonus|pluses|desirable|great if|extra credit)`)
+	ignoreHeading = regexp.MustCompile(`(?i)(benefits|perks|what we offer|about (us|the company|the team)|our (company|mission|values)|compensation|equal opportunity|why join)`)
+	niceCue       = regexp.MustCompile(`(?i)(nice to have|a plus|preferred|bonus|ideally|desirable|familiarity with|exposure to)`)
+	equivalentCue = regexp.MustCompile(`(?i)(or equivalent|equivalent (practical |work )?experience)`)
+	yearsPattern  = regexp.MustCompile(`(?i)(\d+|one|two|three|four|five|six|seven|eight|nine|ten)\s*\+?\s*(?:(?:-|–|to)\s*\d+\s*\+?\s*)?(?:years?|yrs?)`)
+	bulletPrefix  = regexp.MustCompile(`^\s*([-*•·‣◦]|\d+[.)])\s*`)
+)
+
+var numberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+// degreePatterns are checked from the highest level down. Abbreviations
+// such as "BS" only count in job descriptions when they clearly name a
+// degree, as in "BS in Computer Science" or "BS/MS".
+var degreePatterns = []struct {
+	level  models.DegreeLevel
+	loose  *regexp.Regexp
+	strict *regexp.Regexp
+}{
+	{
+		models.DoctorateDegree,
+		regexp.MustCompile(`(?i)(ph\.?\s?d|doctorate|doctoral|doctor of)`),
+		regexp.MustCompile(`(?i)(ph\.?\s?d|doctorate|doctoral|doctor of)`),
+	},
+	{
+		models.MasterDegree,
+		regexp.MustCompile(`(?i)(\bmasters?\b|master'?s|\bmba\b|\bm\.?\s?sc\b|\bm\.?\s?tech\b|\bm\.?\s?eng\b)|(^|[^A-Za-z])(MS|M\.S\.?|MA|M\.A\.?|ME|M\.E\.?)([^A-Za-z]|$)`),
+		regexp.MustCompile(`(?i)(\bmasters?\b|master'?s|\bmba\b|\bm\.?\s?sc\b|\bm\.?\s?tech\b|\bm\.?\s?eng\b)|(^|[^A-Za-z])(MS|M\.S\.|MA|M\.A\.)(\s+in\b|\s*/|\s+degree|,?\s+or\b)`),
+	},
+	{
+		models.BachelorDegree,
+		regexp.MustCompile(`(?i)(\bbachelors?\b|bachelor'?s|undergraduate degree|\bb\.?\s?sc\b|\bb\.?\s?tech\b|\bb\.?\s?eng\b|\bbba\b)|(^|[^A-Za-z])(BS|B\.S\.?|BA|B\.A\.?|BE|B\.E\.?)([^A-Za-z]|$)`),
+		regexp.MustCompile(`(?i)(\bbachelors?\b|bachelor'?s|undergraduate degree|\bb\.?\s?sc\b|\bb\.?\s?tech\b|\bb\.?\s?eng\b|\bbba\b)|(^|[^A-Za-z])(BS|B\.S\.|BA|B\.A\.)(\s+in\b|\s*/|\s+degree|,?\s+or\b)`),
+	},
+	{
+		models.AssociateDegree,
+		regexp.MustCompile(`(?i)associate'?s? degree`),
+		regexp.MustCompile(`(?i)associate'?s? degree`),
+	},
+}
+
+var genericDegree = regexp.MustCompile(`(?i)\b(degree|diploma)\b`)
+
+var certificationPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9])(AWS Certified(?: [A-Z][A-Za-z-]*){1,4}|Google (?:Cloud )?Professional(?: [A-Z][A-Za-z-]*){1,3}|Azure (?:Administrator|Developer|Solutions Architect)(?: [A-Z][A-Za-z-]*){0,2}|Certified Scrum ?Master|(?:CompTIA )?Security\+|CKAD|CKA|CKS|PMP|CISSP|CISM|CISA|CCNA|CCNP|CPA|CFA|CSM|PSM)(?:[^A-Za-z0-9]|$)`)
+
+var seniorityPatterns = []struct {
+	level models.SeniorityLevel
+	title *regexp.Regexp
+	text  *regexp.Regexp
+}{
+	{models.LeadSeniority, regexp.MustCompile(`(?i)\b(lead|staff|principal|head of|architect)\b`), regexp.MustCompile(`(?i)\b(staff[- ]level|principal[- ]level|lead[- ]level)\b`)},
+	{models.SeniorSeniority, regexp.MustCompile(`(?i)\b(senior|sr)\b`), regexp.MustCompile(`(?i)\b(senior[- ]level)\b`)},
+	{models.MidSeniority, regexp.MustCompile(`(?i)\b(mid[- ]level|intermediate)\b`), regexp.MustCompile(`(?i)\b(mid[- ]level)\b`)},
+	{models.EntrySeniority, regexp.MustCompile(`(?i)\b(junior|jr|entry[- ]level|graduate|new grad|intern|internship|associate)\b`), regexp.MustCompile(`(?i)\b(entry[- ]level|new grads?|recent graduates?)\b`)},
+}
+
+// seniorityYears is the experience each seniority level usually implies.
+var seniorityYears = map[models.SeniorityLevel]float64{
+	models.EntrySeniority:  0,
+	models.MidSeniority:    2,
+	models.SeniorSeniority: 5,
+	models.LeadSeniority:   8,
+}
+
+type lineContext int
+
+const (
+	mustContext lineContext = iota
+	niceContext
+	ignoredContext
+)
+
+// skillForm is one way of writing a skill.
+type skillForm struct {
+	skill  string
+	tokens []string
+	exact  bool
+}
+
+func skillForms(name string, aliases []string) []skillForm {
+	forms := []skillForm{}
+	for _, form := range append([]string{name}, aliases...) {
+		lower := textTokens(form)
+		if len(lower) == 0 {
+			continue
+		}
+		exact := len(lower) == 1 && caseSensitiveSkills[lower[0]]
+		tokens := lower
+		if exact {
+			tokens = originalTokens(form)
+		}
+		forms = append(forms, skillForm{skill: name, tokens: tokens, exact: exact})
+	}
+	return forms
+}
+
+// skillVocabulary returns the known skills plus extra ones, e.g. from the
+// resume being compared, so skills outside the built-in list are found too.
+func skillVocabulary(extra []string) map[string][]skillForm {
+	vocabulary := map[string][]skillForm{}
+	covered := map[string]bool{}
+	for name, aliases := range knownSkills {
+		vocabulary[name] = skillForms(name, aliases)
+		for _, form := range vocabulary[name] {
+			covered[strings.Join(textTokens(strings.Join(form.tokens, " ")), " ")] = true
+		}
+	}
+	for _, name := range extra {
+		if key := normalizeText(name); key != "" && !covered[key] {
+			covered[key] = true
+			vocabulary[name] = skillForms(name, nil)
+		}
+	}
+	return vocabulary
+}
+
+func originalTokens(text string) []string {
+	var tokens []string
+	for _, span := range tokenSpans(text) {
+		tokens = append(tokens, text[span[0]:span[1]])
+	}
+	return tokens
+}
+
+// containsForm reports whether the form appears as consecutive words.
+func containsForm(lower []string, original []string, form skillForm) bool {
+	words := lower
+	if form.exact {
+		words = original
+	}
+	for i := 0; i+len(form.tokens) <= len(words); i++ {
+		match := true
+		for j, token := range form.tokens {
+			if words[i+j] != token {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSkill(text string, forms []skillForm) bool {
+	lower, original := textTokens(text), originalTokens(text)
+	for _, form := range forms {
+		if containsForm(lower, original, form) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractRequirements reads the requirements out of a job title and
+// description. Lines under headings such as "Nice to have", or that say
+// something is "a plus", count as nice-to-haves; sections about benefits
+// or the company are skipped.
+func ExtractRequirements(title string, description string, extraSkills []string) models.JobRequirements {
+	requirements := models.JobRequirements{
+		MustHaveSkills:   []string{},
+		NiceToHaveSkills: []string{},
+		Certifications:   []string{},
+		SeniorityCues:    []string{},
+	}
+	vocabulary := skillVocabulary(extraSkills)
+	names := make([]string, 0, len(vocabulary))
+	for name := range vocabulary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	must := map[string]bool{}
+	nice := map[string]bool{}
+	certifications := map[string]bool{}
+	mustDegree, niceDegree := models.NoDegree, models.NoDegree
+	degreeFlexible := false
+
+	context := mustContext
+	for _, line := range strings.Split(description, "\n") {
+		bulleted := bulletPrefix.MatchString(line)
+		line = strings.TrimSpace(bulletPrefix.ReplaceAllString(line, ""))
+		if line == "" {
+			continue
+		}
+		if heading, ok := headingContext(line); ok && !bulleted {
+			context = heading
+			continue
+		}
+		if context == ignoredContext {
+			continue
+		}
+		lineNice := context == niceContext || niceCue.MatchString(line)
+
+		lower, original := textTokens(line), originalTokens(line)
+		for _, name := range names {
+			for _, form := range vocabulary[name] {
+				if containsForm(lower, original, form) {
+					if lineNice {
+						nice[name] = true
+					} else {
+						must[name] = true
+					}
+					break
+				}
+			}
+		}
+
+		if lowerLine := strings.ToLower(line); !lineNice && (strings.Contains(lowerLine, "experience") || strings.Contains(lowerLine, "years of")) {
+			for _, match := range yearsPattern.FindAllStringSubmatch(line, -1) {
+				years, ok := numberWords[strings.ToLower(match[1])]
+				if !ok {
+					years, _ = strconv.Atoi(match[1])
+				}
+				if years > requirements.MinYearsExperience && years <= 30 {
+					requirements.MinYearsExperience = years
+				}
+			}
+		}
+
+		if level := lowestDegree(line); level != models.NoDegree {
+			if equivalentCue.MatchString(line) {
+				degreeFlexible = true
+			}
+			if lineNice {
+				niceDegree = lowerDegree(niceDegree, level)
+			} else {
+				mustDegree = lowerDegree(mustDegree, level)
+			}
+		}
+
+		for _, match := range certificationPattern.FindAllStringSubmatch(line, -1) {
+			if !certifications[match[1]] {
+				certifications[match[1]] = true
+				requirements.Certifications = append(requirements.Certifications, match[1])
+			}
+		}
+	}
+
+	for _, name := range names {
+		if must[name] {
+			requirements.MustHaveSkills = append(requirements.MustHaveSkills, name)
+		} else if nice[name] {
+			requirements.NiceToHaveSkills = append(requirements.NiceToHaveSkills, name)
+		}
+	}
+
+	if mustDegree != models.NoDegree {
+		requirements.Degree = mustDegree
+		requirements.DegreeRequired = !degreeFlexible
+	} else {
+		requirements.Degree = niceDegree
+	}
+
+	requirements.Seniority, requirements.SeniorityCues = seniorityCues(title, description)
+	return requirements
+}
+
+// headingContext recognises a short line introducing a section. Headings
+// end in a colon or are only a few words long.
+func headingContext(line string) (lineContext, bool) {
+	words := len(strings.Fields(line))
+	if words > 8 || words > 4 && !strings.HasSuffix(line, ":") || strings.HasSuffix(line, ".") {
+		return mustContext, false
+	}
+	switch {
+	case ignoreHeading.MatchString(line):
+		return ignoredContext, true
+	case niceHeading.MatchString(line):
+		return niceContext, true
+	case mustHeading.MatchString(line):
+		return mustContext, true
+	case strings.HasSuffix(line, ":"):
+		// An unknown section such as "Responsibilities:" still describes
+		// the job.
+		return mustContext, true
+	}
+	return mustContext, false
+}
+
+// degreeLevels returns every degree level mentioned in the text. Job
+// descriptions use the strict patterns; resume education entries are
+// known to name a degree and use the loose ones.
+func degreeLevels(text string, strict bool) []models.DegreeLevel {
+	levels := []models.DegreeLevel{}
+	for _, pattern := range degreePatterns {
+		re := pattern.loose
+		if strict {
+			re = pattern.strict
+		}
+		if re.MatchString(text) {
+			levels = append(levels, pattern.level)
+		}
+	}
+	return levels
+}
+
+// lowestDegree returns the lowest degree a job description line accepts,
+// e.g. a bachelor's for "BS or MS in Computer Science".
+func lowestDegree(line string) models.DegreeLevel {
+	levels := degreeLevels(line, true)
+	if len(levels) == 0 {
+		if genericDegree.MatchString(line) && !strings.Contains(strings.ToLower(line), "degree of") {
+			return models.BachelorDegree
+		}
+		return models.NoDegree
+	}
+	return levels[len(levels)-1]
+}
+
+func lowerDegree(a models.DegreeLevel, b models.DegreeLevel) models.DegreeLevel {
+	if a == models.NoDegree || models.DegreeRank[b] < models.DegreeRank[a] {
+		return b
+	}
+	return a
+}
+
+// seniorityCues reads the seniority from the job title, or failing that
+// from explicit phrases such as "senior-level" in the description. Words
+// like "junior" in a description often describe the team, not the role.
+func seniorityCues(title string, description string) (models.SeniorityLevel, []string) {
+	for _, source := range []string{title, description} {
+		for _, pattern := range seniorityPatterns {
+			re := pattern.title
+			if source != title {
+				re = pattern.text
+			}
+			if matches := re.FindAllString(source, -1); len(matches) > 0 {
+				return pattern.level, dedupeStrings(matches)
+			}
+		}
+	}
+	return "", []string{}
+}
+
+func dedupeStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		key := strings.ToLower(value)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// ResumeYearsOfExperience adds up the work experience on a resume. Jobs
+// that overlap are only counted once.
+func ResumeYearsOfExperience(resume models.Resume, now time.Time) float64 {
+	type span struct{ start, end time.Time }
+	spans := []span{}
+	for _, work := range resume.WorkExperience {
+		end := work.EndDate
+		if work.IsWorking {
+			end = now
+		}
+		if work.StartDate.IsZero() || end.IsZero() || !end.After(work.StartDate) {
+			continue
+		}
+		spans = append(spans, span{work.StartDate, end})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+
+	var total time.Duration
+	var current *span
+	for i := range spans {
+		if current != nil && !spans[i].start.After(current.end) {
+			if spans[i].end.After(current.end) {
+				current.end = spans[i].end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &spans[i]
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+
+	years := total.Hours() / (365.25 * 24)
+	return math.Round(years*10) / 10
+}
+
+// ResumeDegree returns the highest completed degree on a resume and the
+// highest degree still in progress.
+func ResumeDegree(resume models.Resume) (models.DegreeLevel, models.DegreeLevel) {
+	completed, enrolled := models.NoDegree, models.NoDegree
+	for _, education := range resume.Education {
+		for _, level := range degreeLevels(education.Name, false) {
+			if education.IsEnrolled {
+				if models.DegreeRank[level] > models.DegreeRank[enrolled] {
+					enrolled = level
+				}
+			} else if models.DegreeRank[level] > models.DegreeRank[completed] {
+				completed = level
+			}
+		}
+	}
+	return completed, enrolled
+}
+
+// CheckRequirements compares job requirements with a resume and returns a
+// checklist marking each requirement as met, partially met or missing.
+func CheckRequirements(requirements models.JobRequirements, resume models.Resume, now time.Time) models.RequirementReport {
+	resume = ApplyResumeLayout(resume)
+	years := ResumeYearsOfExperience(resume, now)
+	degree, enrolled := ResumeDegree(resume)
+	report := models.RequirementReport{
+		Requirements: requirements,
+		ResumeYears:  years,
+		ResumeDegree: degree,
+		Checklist:    []models.RequirementCheck{},
+	}
+	add := func(check models.RequirementCheck) {
+		if check.Required && check.Status == models.RequirementMissing {
+			report.MissingRequired++
+		}
+		report.Checklist = append(report.Checklist, check)
+	}
+
+	if requirements.MinYearsExperience > 0 {
+		required := float64(requirements.MinYearsExperience)
+		check := models.RequirementCheck{
+			Category:    models.ExperienceRequirement,
+			Requirement: fmt.Sprintf("%d+ years of experience", requirements.MinYearsExperience),
+			Required:    true,
+			Status:      models.RequirementMissing,
+			Detail:      fmt.Sprintf("%.1f years of work experience on the resume", years),
+		}
+		if years >= required {
+			check.Status = models.RequirementMet
+		} else if years >= required*0.75 {
+			check.Status = models.RequirementPartial
+		}
+		add(check)
+	}
+
+	if requirements.Degree != models.NoDegree {
+		check := models.RequirementCheck{
+			Category:    models.DegreeRequirement,
+			Requirement: string(requirements.Degree) + " degree",
+			Required:    requirements.DegreeRequired,
+			Status:      models.RequirementMissing,
+			Detail:      "no matching degree found in education",
+		}
+		switch {
+		case models.DegreeRank[degree] >= models.DegreeRank[requirements.Degree]:
+			check.Status = models.RequirementMet
+			check.Detail = string(degree) + " degree on the resume"
+		case models.DegreeRank[enrolled] >= models.DegreeRank[requirements.Degree]:
+			check.Status = models.RequirementPartial
+			check.Detail = string(enrolled) + " degree in progress"
+		case !requirements.DegreeRequired && requirements.MinYearsExperience > 0 && years >= float64(requirements.MinYearsExperience):
+			check.Status = models.RequirementPartial
+			check.Detail = "equivalent experience may be accepted instead"
+		}
+		add(check)
+	}
+
+	skillNames := append(append([]string{}, resume.Skills...), projectTechnologies(resume)...)
+	mentions := resumeMentions(resume)
+	vocabulary := skillVocabulary(resume.Skills)
+	checkSkills := func(skills []string, required bool) {
+		for _, skill := range skills {
+			forms, ok := vocabulary[skill]
+			if !ok {
+				forms = skillForms(skill, nil)
+			}
+			check := models.RequirementCheck{
+				Category:    models.SkillRequirement,
+				Requirement: skill,
+				Required:    required,
+				Status:      models.RequirementMissing,
+				Detail:      "not found on the resume",
+			}
+			if listsSkill(skillNames, forms) {
+				check.Status = models.RequirementMet
+				check.Detail = "listed in skills"
+			} else if containsSkill(mentions, forms) {
+				check.Status = models.RequirementPartial
+				check.Detail = "mentioned in experience but not listed in skills"
+			}
+			add(check)
+		}
+	}
+	checkSkills(requirements.MustHaveSkills, true)
+
+	held := strings.ToLower(certificationText(resume))
+	for _, certification := range requirements.Certifications {
+		check := models.RequirementCheck{
+			Category:    models.CertificationRequirement,
+			Requirement: certification,
+			Required:    true,
+			Status:      models.RequirementMissing,
+			Detail:      "not found in certifications",
+		}
+		if strings.Contains(held, strings.ToLower(certification)) {
+			check.Status = models.RequirementMet
+			check.Detail = "listed in certifications"
+		} else if fields := strings.Fields(certification); len(fields) > 2 && strings.Contains(held, strings.ToLower(strings.Join(fields[:2], " "))) {
+			check.Status = models.RequirementPartial
+			check.Detail = "a related certification is listed"
+		}
+		add(check)
+	}
+
+	checkSkills(requirements.NiceToHaveSkills, false)
+
+	if requirements.Seniority != "" {
+		floor := seniorityYears[requirements.Seniority]
+		check := models.RequirementCheck{
+			Category:    models.SeniorityRequirement,
+			Requirement: string(requirements.Seniority) + " level",
+			Required:    false,
+			Status:      models.RequirementMet,
+			Detail:      fmt.Sprintf("%s roles usually ask for %.0f+ years; the resume shows %.1f", requirements.Seniority, floor, years),
+		}
+		if years < floor-2 {
+			check.Status = models.RequirementMissing
+		} else if years < floor {
+			check.Status = models.RequirementPartial
+		}
+		add(check)
+	}
+
+	return report
+}
+
+func listsSkill(skills []string, forms []skillForm) bool {
+	for _, skill := range skills {
+		lower := textTokens(skill)
+		for _, form := range forms {
+			// A skills list is unambiguous, so case does not matter here.
+			loose := skillForm{skill: form.skill, tokens: textTokens(strings.Join(form.tokens, " "))}
+			if containsForm(lower, lower, loose) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func projectTechnologies(resume models.Resume) []string {
+	technologies := []string{}
+	for _, project := range resume.Projects {
+		technologies = append(technologies, project.Technologies...)
+	}
+	return technologies
+}
+
+// resumeMentions joins the free text of a resume that can show a skill in
+// use without it being listed.
+func resumeMentions(resume models.Resume) string {
+	parts := []string{}
+	if resume.Summary != nil {
+		parts = append(parts, *resume.Summary)
+	}
+	for _, work := range resume.WorkExperience {
+		parts = append(parts, work.RoleTitle)
+		parts = append(parts, work.BulletPoints...)
+	}
+	for _, project := range resume.Projects {
+		if project.Description != nil {
+			parts = append(parts, *project.Description)
+		}
+		parts = append(parts, project.BulletPoints...)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func certificationText(resume models.Resume) string {
+	parts := []string{}
+	for _, certification := range resume.Certifications {
+		parts = append(parts, certification.Title, certification.Description)
+	}
+	return strings.Join(parts, "\n")
+}
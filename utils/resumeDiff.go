@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"crafter/models"
+	"sort"
+	"strings"
+)
+
+// rewordThreshold is the minimum word overlap for a removed and an added
+// bullet to be reported as one reworded bullet.
+const rewordThreshold = 0.4
+
+// DiffResumes compares the visible content of two resumes. Work experience
+// is aligned by company and role and projects by name, so reordering
+// entries does not show up as a change.
+func DiffResumes(base models.Resume, target models.Resume) models.ResumeDiff {
+	base = ApplyResumeLayout(base)
+	target = ApplyResumeLayout(target)
+
+	diff := models.ResumeDiff{
+		BaseID:   base.ID,
+		TargetID: target.ID,
+		Skills:   diffSets(base.Skills, target.Skills),
+	}
+
+	var baseSummary, targetSummary string
+	if base.Summary != nil {
+		baseSummary = *base.Summary
+	}
+	if target.Summary != nil {
+		targetSummary = *target.Summary
+	}
+	diff.SummaryChanged = normalizeText(baseSummary) != normalizeText(targetSummary)
+
+	type entry struct {
+		key          string
+		bullets      []string
+		technologies []string
+	}
+
+	diffEntries := func(baseEntries []entry, targetEntries []entry, withTechnologies bool) []models.EntryDiff {
+		diffs := []models.EntryDiff{}
+		// Entries sharing a key, such as two stints in the same role, are
+		// matched in the order they appear.
+		targetByKey := map[string][]int{}
+		for i, e := range targetEntries {
+			key := normalizeText(e.key)
+			targetByKey[key] = append(targetByKey[key], i)
+		}
+		matched := make([]bool, len(targetEntries))
+
+		for _, b := range baseEntries {
+			key := normalizeText(b.key)
+			candidates := targetByKey[key]
+			if len(candidates) == 0 {
+				diffs = append(diffs, models.EntryDiff{
+					Key:     b.key,
+					Status:  models.DiffRemoved,
+					Bullets: diffBullets(b.bullets, nil),
+				})
+				continue
+			}
+			targetByKey[key] = candidates[1:]
+			matched[candidates[0]] = true
+			t := targetEntries[candidates[0]]
+
+			entryDiff := models.EntryDiff{Key: b.key, Bullets: diffBullets(b.bullets, t.bullets)}
+			changed := len(entryDiff.Bullets.Added)+len(entryDiff.Bullets.Removed)+len(entryDiff.Bullets.Reworded) > 0
+			if withTechnologies {
+				technologies := diffSets(b.technologies, t.technologies)
+				entryDiff.Technologies = &technologies
+				changed = changed || len(technologies.Added)+len(technologies.Removed) > 0
+			}
+			entryDiff.Status = models.DiffUnchanged
+			if changed {
+				entryDiff.Status = models.DiffChanged
+			}
+			diffs = append(diffs, entryDiff)
+		}
+
+		for i, t := range targetEntries {
+			if !matched[i] {
+				diffs = append(diffs, models.EntryDiff{
+					Key:     t.key,
+					Status:  models.DiffAdded,
+					Bullets: diffBullets(nil, t.bullets),
+				})
+			}
+		}
+		return diffs
+	}
+
+	var baseWork, targetWork []entry
+	for _, work := range base.WorkExperience {
+		baseWork = append(baseWork, entry{key: work.CompanyName + " - " + work.RoleTitle, bullets: work.BulletPoints})
+	}
+	for _, work := range target.WorkExperience {
+		targetWork = append(targetWork, entry{key: work.CompanyName + " - " + work.RoleTitle, bullets: work.BulletPoints})
+	}
+	diff.WorkExperience = diffEntries(baseWork, targetWork, false)
+
+	var baseProjects, targetProjects []entry
+	for _, project := range base.Projects {
+		baseProjects = append(baseProjects, entry{key: project.Name, bullets: project.BulletPoints, technologies: project.Technologies})
+	}
+	for _, project := range target.Projects {
+		targetProjects = append(targetProjects, entry{key: project.Name, bullets: project.BulletPoints, technologies: project.Technologies})
+	}
+	diff.Projects = diffEntries(baseProjects, targetProjects, true)
+
+	return diff
+}
+
+// diffBullets matches identical bullets first and then pairs the remaining
+// ones by word overlap, best match first.
+func diffBullets(base []string, target []string) models.BulletDiff {
+	diff := models.BulletDiff{
+		Added:    []string{},
+		Removed:  []string{},
+		Reworded: []models.RewordedBullet{},
+	}
+
+	targetUsed := make([]bool, len(target))
+	var baseLeft []int
+	for i, b := range base {
+		found := false
+		for j, t := range target {
+			if !targetUsed[j] && normalizeText(b) == normalizeText(t) {
+				targetUsed[j] = true
+				found = true
+				break
+			}
+		}
+		if found {
+			diff.Unchanged++
+		} else {
+			baseLeft = append(baseLeft, i)
+		}
+	}
+
+	type pair struct {
+		base, target int
+		score        float64
+	}
+	var pairs []pair
+	for _, i := range baseLeft {
+		for j := range target {
+			if targetUsed[j] {
+				continue
+			}
+			if score := wordSimilarity(base[i], target[j]); score >= rewordThreshold {
+				pairs = append(pairs, pair{i, j, score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].score > pairs[b].score })
+
+	baseUsed := map[int]bool{}
+	for _, p := range pairs {
+		if baseUsed[p.base] || targetUsed[p.target] {
+			continue
+		}
+		baseUsed[p.base] = true
+		targetUsed[p.target] = true
+		diff.Reworded = append(diff.Reworded, models.RewordedBullet{
+			Base:       base[p.base],
+			Target:     target[p.target],
+			Similarity: p.score,
+		})
+	}
+
+	for _, i := range baseLeft {
+		if !baseUsed[i] {
+			diff.Removed = append(diff.Removed, base[i])
+		}
+	}
+	for j, t := range target {
+		if !targetUsed[j] {
+			diff.Added = append(diff.Added, t)
+		}
+	}
+	return diff
+}
+
+func diffSets(base []string, target []string) models.SetDiff {
+	diff := models.SetDiff{Added: []string{}, Removed: []string{}}
+
+	inBase := map[string]bool{}
+	for _, value := range base {
+		inBase[normalizeText(value)] = true
+	}
+	inTarget := map[string]bool{}
+	for _, value := range target {
+		inTarget[normalizeText(value)] = true
+	}
+
+	for _, value := range base {
+		if !inTarget[normalizeText(value)] {
+			diff.Removed = append(diff.Removed, value)
+		}
+	}
+	for _, value := range target {
+		if !inBase[normalizeText(value)] {
+			diff.Added = append(diff.Added, value)
+		}
+	}
+	return diff
+}
+
+// wordSimilarity is the Jaccard index of the word sets of a and b.
+func wordSimilarity(a string, b string) float64 {
+	wordsA := map[string]bool{}
+	for _, word := range textTokens(a) {
+		wordsA[word] = true
+	}
+	wordsB := map[string]bool{}
+	for _, word := range textTokens(b) {
+		wordsB[word] = true
+	}
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// textTokens splits text into lower-cased words, keeping characters that
+// commonly appear inside technical terms such as "c++", "node.js" or "p99".
+func textTokens(text string) []string {
+	var tokens []string
+	for _, span := range tokenSpans(text) {
+		tokens = append(tokens, strings.ToLower(text[span[0]:span[1]]))
+	}
+	return tokens
+}
+
+func normalizeText(text string) string {
+	return strings.Join(textTokens(text), " ")
+}
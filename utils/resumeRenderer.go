@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"crafter/models"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	JSONFormat     = "json"
+	MarkdownFormat = "markdown"
+)
+
+type resumeSection struct {
+	key     string
+	heading string
+	write   func(b *strings.Builder, resume models.Resume, locale resumeLocale)
+}
+
+// resumeSections is the default render order of the fixed resume sections.
+// Headings are the English fallback; see resumeLocales for translations.
+var resumeSections = []resumeSection{
+	{"summary", "Summary", writeSummary},
+	{"skills", "Skills", writeSkills},
+	{"work_experience", "Work Experience", writeWorkExperience},
+	{"education", "Education", writeEducation},
+	{"projects", "Projects", writeProjects},
+	{"certifications", "Certifications", writeCertifications},
+	{"languages", "Languages", writeLanguages},
+	{"honors_awards", "Honors & Awards", writeHonorsAwards},
+	{"extracurriculars", "Extracurriculars", writeExtracurriculars},
+}
+
+// RenderResume renders a resume in the requested export format and returns
+// the body together with its content type. Hidden sections and entries are
+// dropped and the layout's section order is followed.
+func RenderResume(resume models.Resume, format string) ([]byte, string, error) {
+	resume = ApplyResumeLayout(resume)
+
+	switch format {
+	case "", JSONFormat:
+		body, err := json.Marshal(ExportResume(resume))
+		return body, "application/json; charset=utf-8", err
+	case MarkdownFormat:
+		return []byte(renderMarkdown(resume)), "text/markdown; charset=utf-8", nil
+	}
+	return nil, "", fmt.Errorf("unsupported export format %q", format)
+}
+
+// ExportResume projects a resume onto what an export may show. Sections
+// without content are left out, like in the Markdown export.
+func ExportResume(resume models.Resume) models.ResumeExport {
+	export := models.ResumeExport{
+		Name:          resume.Name,
+		Email:         resume.Email,
+		PhoneNumber:   resume.PhoneNumber,
+		Location:      resume.Location,
+		LinkedInLink:  resume.LinkedInLink,
+		GitHubLink:    resume.GitHubLink,
+		PortfolioLink: resume.PortfolioLink,
+		Locale:        resume.Locale,
+		Sections:      []models.ExportSection{},
+	}
+
+	locale := localeFor(resume.Locale)
+	headings := map[string]string{}
+	for _, section := range resumeSections {
+		headings[section.key] = section.heading
+	}
+	custom := map[string]models.CustomSection{}
+	for _, section := range resume.CustomSections {
+		custom[section.ID.Hex()] = section
+	}
+
+	for _, key := range ResumeSectionOrder(resume) {
+		if section, ok := custom[key]; ok {
+			if len(section.Entries) > 0 {
+				export.Sections = append(export.Sections, models.ExportSection{
+					Key:     "custom",
+					Heading: section.Title,
+					Type:    section.Type,
+					Content: section.Entries,
+				})
+			}
+			continue
+		}
+		if content, ok := sectionContent(resume, key); ok {
+			export.Sections = append(export.Sections, models.ExportSection{
+				Key:     key,
+				Heading: locale.heading(key, headings[key]),
+				Content: content,
+			})
+		}
+	}
+	return export
+}
+
+// sectionContent returns the content of a fixed section, or false when the
+// section is empty.
+func sectionContent(resume models.Resume, key string) (interface{}, bool) {
+	switch key {
+	case "summary":
+		if resume.Summary != nil && *resume.Summary != "" {
+			return *resume.Summary, true
+		}
+		return nil, false
+	case "skills":
+		return resume.Skills, len(resume.Skills) > 0
+	case "work_experience":
+		return resume.WorkExperience, len(resume.WorkExperience) > 0
+	case "education":
+		return resume.Education, len(resume.Education) > 0
+	case "projects":
+		return resume.Projects, len(resume.Projects) > 0
+	case "certifications":
+		return resume.Certifications, len(resume.Certifications) > 0
+	case "languages":
+		return resume.Languages, len(resume.Languages) > 0
+	case "honors_awards":
+		return resume.HonorsAwards, len(resume.HonorsAwards) > 0
+	case "extracurriculars":
+		return resume.Extracurriculars, len(resume.Extracurriculars) > 0
+	}
+	return nil, false
+}
+
+func renderMarkdown(resume models.Resume) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", resume.Name)
+
+	var contact []string
+	for _, value := range []string{resume.Email, resume.PhoneNumber, resume.Location} {
+		if value != "" {
+			contact = append(contact, value)
+		}
+	}
+	for _, link := range []*string{resume.LinkedInLink, resume.GitHubLink, resume.PortfolioLink} {
+		if link != nil && *link != "" {
+			contact = append(contact, *link)
+		}
+	}
+	if len(contact) > 0 {
+		b.WriteString(strings.Join(contact, " | ") + "\n\n")
+	}
+
+	locale := localeFor(resume.Locale)
+
+	fixed := map[string]resumeSection{}
+	for _, section := range resumeSections {
+		fixed[section.key] = section
+	}
+	custom := map[string]models.CustomSection{}
+	for _, section := range resume.CustomSections {
+		custom[section.ID.Hex()] = section
+	}
+
+	for _, key := range ResumeSectionOrder(resume) {
+		var body strings.Builder
+		var heading string
+		if section, ok := fixed[key]; ok {
+			section.write(&body, resume, locale)
+			heading = locale.heading(section.key, section.heading)
+		} else if section, ok := custom[key]; ok {
+			writeCustomSection(&body, section, locale)
+			heading = section.Title
+		}
+		if body.Len() == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", heading, strings.TrimRight(body.String(), "\n"))
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeSummary(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	if resume.Summary != nil && *resume.Summary != "" {
+		b.WriteString(*resume.Summary + "\n")
+	}
+}
+
+func writeSkills(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	if len(resume.Skills) > 0 {
+		b.WriteString(strings.Join(resume.Skills, ", ") + "\n")
+	}
+}
+
+func writeWorkExperience(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, work := range resume.WorkExperience {
+		fmt.Fprintf(b, "### %s, %s\n", work.RoleTitle, work.CompanyName)
+		if line := joinNonEmpty(" | ", locale.formatDateRange(work.StartDate, work.EndDate, work.IsWorking), work.Location); line != "" {
+			fmt.Fprintf(b, "%s\n", line)
+		}
+		writeBullets(b, work.BulletPoints)
+		b.WriteString("\n")
+	}
+}
+
+func writeEducation(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, education := range resume.Education {
+		end := education.EndDate
+		if education.IsEnrolled && !education.ExpectedGraduationDate.IsZero() {
+			end = education.ExpectedGraduationDate
+		}
+		fmt.Fprintf(b, "### %s\n", education.Name)
+		line := joinNonEmpty(" | ", locale.formatDateRange(education.StartDate, end, false), education.Location)
+		if education.GPA > 0 {
+			line = joinNonEmpty(" | ", line, fmt.Sprintf("GPA %.2f", education.GPA))
+		}
+		fmt.Fprintf(b, "%s\n\n", line)
+	}
+}
+
+func writeProjects(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, project := range resume.Projects {
+		fmt.Fprintf(b, "### %s\n", project.Name)
+		if line := joinNonEmpty(" | ", locale.formatDateRange(project.StartDate, project.EndDate, false), strings.Join(project.Technologies, ", ")); line != "" {
+			fmt.Fprintf(b, "%s\n", line)
+		}
+		if project.Description != nil && *project.Description != "" {
+			fmt.Fprintf(b, "%s\n", *project.Description)
+		}
+		if project.ProjectUrl != nil && *project.ProjectUrl != "" {
+			fmt.Fprintf(b, "%s\n", *project.ProjectUrl)
+		}
+		writeBullets(b, project.BulletPoints)
+		b.WriteString("\n")
+	}
+}
+
+func writeCertifications(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, certification := range resume.Certifications {
+		fmt.Fprintf(b, "- **%s**", certification.Title)
+		if certification.Description != "" {
+			fmt.Fprintf(b, ": %s", certification.Description)
+		}
+		if certification.CertificateLink != "" {
+			fmt.Fprintf(b, " (%s)", certification.CertificateLink)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeLanguages(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	if len(resume.Languages) > 0 {
+		b.WriteString(strings.Join(resume.Languages, ", ") + "\n")
+	}
+}
+
+func writeHonorsAwards(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, award := range resume.HonorsAwards {
+		writeTitled(b, award.Title, award.Description)
+	}
+}
+
+func writeExtracurriculars(b *strings.Builder, resume models.Resume, locale resumeLocale) {
+	for _, activity := range resume.Extracurriculars {
+		writeTitled(b, activity.ActivityName, activity.Description)
+	}
+}
+
+func writeTitled(b *strings.Builder, title string, description string) {
+	fmt.Fprintf(b, "- **%s**", title)
+	if description != "" {
+		fmt.Fprintf(b, ": %s", description)
+	}
+	b.WriteString("\n")
+}
+
+func writeBullets(b *strings.Builder, bullets []string) {
+	for _, bullet := range bullets {
+		fmt.Fprintf(b, "- %s\n", bullet)
+	}
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	var parts []string
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"crafter/models"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// SearchSections are the section filters accepted by SearchResumes.
+var SearchSections = []string{"summary", "skills", "work_experience", "projects", "custom_sections"}
+
+// SearchIndexFields are the resume fields covered by the text index that
+// SearchQuery runs against. They are the fields SearchResumes looks in.
+var SearchIndexFields = []string{
+	"summary",
+	"skills",
+	"work_experience.bullet_points",
+	"projects.description",
+	"projects.bullet_points",
+	"custom_sections.entries.text",
+	"custom_sections.entries.title",
+	"custom_sections.entries.subtitle",
+	"custom_sections.entries.description",
+	"custom_sections.entries.key",
+	"custom_sections.entries.value",
+}
+
+// SearchQuery turns a search into a $text search string. Every term is
+// quoted so the index only returns resumes that hold all of them.
+func SearchQuery(query string) string {
+	var quoted []string
+	for _, term := range textTokens(query) {
+		quoted = append(quoted, `"`+term+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// SearchResumes finds every piece of text in the given resumes that contains
+// all query terms. Terms match whole words, ignoring case, as the text
+// index does. An empty section searches all sections.
+func SearchResumes(resumes []models.Resume, query string, section string) []models.SearchHit {
+	terms := textTokens(query)
+	hits := []models.SearchHit{}
+	if len(terms) == 0 {
+		return hits
+	}
+
+	for _, resume := range resumes {
+		match := func(hit models.SearchHit) {
+			if section != "" && hit.Section != section {
+				return
+			}
+			highlight, score, ok := highlightTerms(hit.Text, terms)
+			if !ok {
+				return
+			}
+			hit.ResumeID = resume.ID
+			hit.Locale = resume.Locale
+			hit.Highlight = highlight
+			hit.Score = score
+			hits = append(hits, hit)
+		}
+
+		if resume.Summary != nil {
+			match(models.SearchHit{Section: "summary", EntryIndex: -1, BulletIndex: -1, Text: *resume.Summary})
+		}
+		for i, skill := range resume.Skills {
+			match(models.SearchHit{Section: "skills", EntryIndex: i, BulletIndex: -1, Text: skill})
+		}
+		for i, work := range resume.WorkExperience {
+			title := work.RoleTitle + ", " + work.CompanyName
+			for j, bullet := range work.BulletPoints {
+				match(models.SearchHit{Section: "work_experience", EntryIndex: i, EntryID: entryHex(work.ID), EntryTitle: title, BulletIndex: j, Text: bullet})
+			}
+		}
+		for i, project := range resume.Projects {
+			if project.Description != nil {
+				match(models.SearchHit{Section: "projects", EntryIndex: i, EntryID: entryHex(project.ID), EntryTitle: project.Name, BulletIndex: -1, Text: *project.Description})
+			}
+			for j, bullet := range project.BulletPoints {
+				match(models.SearchHit{Section: "projects", EntryIndex: i, EntryID: entryHex(project.ID), EntryTitle: project.Name, BulletIndex: j, Text: bullet})
+			}
+		}
+		for _, custom := range resume.CustomSections {
+			for i, entry := range custom.Entries {
+				text := joinNonEmpty(" ", entry.Text, entry.Title, entry.Subtitle, entry.Description, entry.Key, entry.Value)
+				match(models.SearchHit{
+					Section:     "custom_sections",
+					SectionID:   custom.ID.Hex(),
+					EntryIndex:  i,
+					EntryID:     entryHex(entry.ID),
+					EntryTitle:  custom.Title,
+					BulletIndex: -1,
+					Text:        text,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits
+}
+
+func entryHex(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// highlightTerms wraps every word matched by one of the terms in highlight
+// markers. The text around them is HTML escaped, so the highlight is safe to
+// render as HTML. It reports false unless each term matched at least once.
+func highlightTerms(text string, terms []string) (string, int, bool) {
+	matched := make([]bool, len(terms))
+	score := 0
+
+	var b strings.Builder
+	last := 0
+	for _, span := range tokenSpans(text) {
+		word := strings.ToLower(text[span[0]:span[1]])
+		hit := false
+		for i, term := range terms {
+			if word == term {
+				matched[i] = true
+				hit = true
+			}
+		}
+		if !hit {
+			continue
+		}
+		score++
+		b.WriteString(html.EscapeString(text[last:span[0]]))
+		b.WriteString(HighlightStart + html.EscapeString(text[span[0]:span[1]]) + HighlightEnd)
+		last = span[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+
+	for _, ok := range matched {
+		if !ok {
+			return "", 0, false
+		}
+	}
+	return b.String(), score, true
+}
+
+// tokenSpans returns the byte offsets of the words textTokens would produce.
+func tokenSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.'
+		if inWord && start < 0 {
+			start = i
+		} else if !inWord && start >= 0 {
+			spans = append(spans, trimSpan(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, trimSpan(text, start, len(text)))
+	}
+
+	nonEmpty := spans[:0]
+	for _, span := range spans {
+		if span[0] < span[1] {
+			nonEmpty = append(nonEmpty, span)
+		}
+	}
+	return nonEmpty
+}
+
+func trimSpan(text string, start int, end int) [2]int {
+	for start < end && text[start] == '.' {
+		start++
+	}
+	for end > start && text[end-1] == '.' {
+		end--
+	}
+	return [2]int{start, end}
+}
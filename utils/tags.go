@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NormalizeTag makes tags that differ only in case or spacing the same
+// tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(collapseSpace(tag))
+}
+
+// NormalizeTags normalizes every tag and drops empty and repeated ones,
+// keeping the order they were given in. A nil list stays nil so that
+// updates can tell "no tags sent" from "no tags".
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// ParseTagList reads a comma separated ?tags= value.
+func ParseTagList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return NormalizeTags(strings.Split(value, ","))
+}
+
+// RelativeDate resolves the relative dates saved filters use: today,
+// this_week (from Monday), this_month, this_year and Nd for N days ago. All
+// of them name the start of a day in UTC.
+func RelativeDate(value string, now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch value {
+	case "today":
+		return today, true
+	case "this_week":
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), true
+	case "this_month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), true
+	case "this_year":
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), true
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return today.AddDate(0, 0, -n), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CheckSavedFilter checks that a saved filter only holds parameters its
+// list understands.
+func CheckSavedFilter(filter models.SavedFilter) error {
+	allowed := map[string]bool{}
+	for _, param := range models.FilterParams[filter.Target] {
+		allowed[param] = true
+	}
+	for param := range filter.Query {
+		if param == "tags" {
+			return fmt.Errorf("tags belong in the tags field, not in query")
+		}
+		if !allowed[param] {
+			return fmt.Errorf("%s cannot filter %s", param, filter.Target)
+		}
+	}
+	return nil
+}
+
+// ApplySavedFilter adds a saved filter to the query of a list request.
+// Parameters given in the request win over the filter's, while tags add up,
+// so a preset can be narrowed further with more tags.
+func ApplySavedFilter(filter models.SavedFilter, query url.Values) url.Values {
+	merged := url.Values{}
+	for param, value := range filter.Query {
+		merged.Set(param, value)
+	}
+	for param, values := range query {
+		if param != "tags" {
+			merged[param] = values
+		}
+	}
+
+	tags := append([]string{}, filter.Tags...)
+	for _, value := range query["tags"] {
+		tags = append(tags, ParseTagList(value)...)
+	}
+	if tags = NormalizeTags(tags); len(tags) > 0 {
+		merged.Set("tags", strings.Join(tags, ","))
+	}
+	return merged
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"crafter/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimelineSources holds everything that ends up on an application's
+// activity feed. Contacts are used to name the person in interactions.
+type TimelineSources struct {
+	Application  models.JobApplication
+	Notes        []models.ApplicationNote
+	Documents    []models.Document
+	Reminders    []models.Reminder
+	Interactions []models.Interaction
+	Contacts     []models.Contact
+}
+
+// BuildTimeline merges the sources into a single feed, newest first. Events
+// at the same moment are listed status changes first, then notes,
+// documents, reminders and interactions.
+func BuildTimeline(sources TimelineSources) []models.TimelineEvent {
+	events := []models.TimelineEvent{}
+	ref := func(id primitive.ObjectID) *primitive.ObjectID { return &id }
+
+	for _, change := range sources.Application.StatusHistory {
+		title := "Created as " + statusLabel(change.To)
+		if change.From != "" {
+			title = fmt.Sprintf("Moved from %s to %s", statusLabel(change.From), statusLabel(change.To))
+		}
+		events = append(events, models.TimelineEvent{
+			Kind:  models.StatusChangeEvent,
+			At:    change.At,
+			Title: title,
+			Body:  change.Note,
+			Data:  change,
+		})
+	}
+
+	for _, note := range sources.Notes {
+		title := "Note added"
+		if len(note.Revisions) > 0 {
+			title = fmt.Sprintf("Note added (edited %d times)", len(note.Revisions))
+		}
+		events = append(events, models.TimelineEvent{
+			Kind:  models.NoteEvent,
+			At:    note.CreatedAt,
+			RefID: ref(note.ID),
+			Title: title,
+			Body:  note.Body,
+			Data:  note,
+		})
+	}
+
+	for _, document := range sources.Documents {
+		events = append(events, models.TimelineEvent{
+			Kind:  models.DocumentEvent,
+			At:    document.CreatedAt,
+			RefID: ref(document.ID),
+			Title: "Attached " + document.FileName,
+			Body:  document.Description,
+			Data:  document,
+		})
+	}
+
+	for _, reminder := range sources.Reminders {
+		events = append(events, models.TimelineEvent{
+			Kind:  models.ReminderEvent,
+			At:    reminder.DueAt,
+			RefID: ref(reminder.ID),
+			Title: "Reminder: " + strings.ReplaceAll(string(reminder.Kind), "_", " "),
+			Body:  reminder.Message,
+			Data:  reminder,
+		})
+	}
+
+	names := map[primitive.ObjectID]string{}
+	for _, contact := range sources.Contacts {
+		names[contact.ID] = contact.Name
+	}
+	for _, interaction := range sources.Interactions {
+		title := strings.ReplaceAll(string(interaction.Kind), "_", " ")
+		if title != "" {
+			title = strings.ToUpper(title[:1]) + title[1:]
+		}
+		if name := names[interaction.ContactID]; name != "" {
+			title += " with " + name
+		}
+		events = append(events, models.TimelineEvent{
+			Kind:  models.InteractionEvent,
+			At:    interaction.OccurredAt,
+			RefID: ref(interaction.ID),
+			Title: title,
+			Body:  interaction.Summary,
+			Data:  interaction,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.After(events[j].At)
+	})
+	return events
+}
+
+func statusLabel(status models.ApplicationStatus) string {
+	return strings.ReplaceAll(string(status), "_", " ")
+}